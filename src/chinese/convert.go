@@ -0,0 +1,118 @@
+package chinese
+
+import (
+	_ "embed"
+	"encoding/json"
+	"news/src/logger"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//go:embed dictionary.json
+var defaultDictionary []byte
+
+const (
+	// RegionTW 台湾用词
+	RegionTW = "TW"
+
+	// RegionHK 香港用词
+	RegionHK = "HK"
+)
+
+// dictionary 简繁转换词典，regions 为地区用词，优先于通用词组
+type dictionary struct {
+	Characters map[string]string            `json:"characters"`
+	Phrases    map[string]string            `json:"phrases"`
+	Regions    map[string]map[string]string `json:"regions"`
+}
+
+// Converter 简体转繁体，按最长匹配依次查找地区用词和通用词组，其他文字按单字转换，结果确定
+type Converter struct {
+	characters map[rune]rune
+	phrases    map[string]string
+	regions    map[string]map[string]string
+	maxLen     int
+}
+
+var (
+	converter     *Converter
+	converterOnce sync.Once
+)
+
+// Default 使用内置词典的转换器
+func Default() *Converter {
+	converterOnce.Do(func() {
+		var err error
+		if converter, err = NewConverter(defaultDictionary); err != nil {
+			logger.Errorf("Failed to load chinese dictionary: %s", err)
+			converter = &Converter{}
+		}
+	})
+
+	return converter
+}
+
+func NewConverter(data []byte) (*Converter, error) {
+	dict := dictionary{}
+	if err := json.Unmarshal(data, &dict); err != nil {
+		return nil, err
+	}
+
+	c := &Converter{
+		characters: make(map[rune]rune, len(dict.Characters)),
+		phrases:    dict.Phrases,
+		regions:    dict.Regions,
+	}
+	for s, t := range dict.Characters {
+		sr, _ := utf8.DecodeRuneInString(s)
+		tr, _ := utf8.DecodeRuneInString(t)
+		c.characters[sr] = tr
+	}
+	for s := range dict.Phrases {
+		c.maxLen = max(c.maxLen, utf8.RuneCountInString(s))
+	}
+	for _, phrases := range dict.Regions {
+		for s := range phrases {
+			c.maxLen = max(c.maxLen, utf8.RuneCountInString(s))
+		}
+	}
+
+	return c, nil
+}
+
+// ToTraditional 转换为繁体中文，region 为 TW、HK 时使用地区用词，为空时只转换字形
+func (c *Converter) ToTraditional(text, region string) string {
+	runes := []rune(text)
+	b := strings.Builder{}
+	b.Grow(len(text))
+
+	regional := c.regions[region]
+	for i := 0; i < len(runes); {
+		matched := false
+		for n := min(c.maxLen, len(runes)-i); n >= 2 && !matched; n-- {
+			word := string(runes[i : i+n])
+			if t, ok := regional[word]; ok {
+				b.WriteString(t)
+			} else if t, ok = c.phrases[word]; ok {
+				b.WriteString(t)
+			} else {
+				continue
+			}
+			i += n
+			matched = true
+		}
+		if matched {
+			continue
+		}
+
+		if t, ok := c.characters[runes[i]]; ok {
+			b.WriteRune(t)
+		} else {
+			b.WriteRune(runes[i])
+		}
+		i++
+	}
+
+	return b.String()
+}
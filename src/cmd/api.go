@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"news/src/config"
 	"news/src/logger"
+	"news/src/media"
 	"news/src/storage"
 	"news/src/utils"
 	"time"
@@ -20,11 +21,25 @@ func router(g *gin.Engine, ns *storage.NewsService) {
 		})
 	})
 
+	// Static assets
+	if url, dir, ok := media.StaticRoute(); ok {
+		g.Static(url, dir)
+	}
+
 	// News API
 	g.GET("/news/articles/token/:token", utils.ApiHandle(ns.HomeLinkHandler))
+	g.POST("/news/articles/token/:token/edit", utils.ApiHandle(ns.ArticleEditHandler))
+	g.POST("/news/articles/token/:token/edits", utils.ApiHandle(ns.ArticleEditListHandler))
 	g.POST("/news/home", utils.ApiHandle(ns.HomeHandler))
 	g.POST("/news/sitemap/:category/:lang", utils.ApiHandle(ns.HomeListHandler))
 	g.POST("/news/origins", utils.ApiHandle(ns.HomeOriginListHandler))
+	g.POST("/news/categories", utils.ApiHandle(ns.CategoryListHandler))
+	g.POST("/news/categories/rules", utils.ApiHandle(ns.CategoryRuleListHandler))
+	g.POST("/news/stories", utils.ApiHandle(ns.StoryListHandler))
+	g.POST("/news/stories/:token", utils.ApiHandle(ns.StoryHandler))
+	g.POST("/news/topics", utils.ApiHandle(ns.TopicListHandler))
+	g.POST("/news/coins", utils.ApiHandle(ns.CoinListHandler))
+	g.POST("/news/coins/:slug", utils.ApiHandle(ns.CoinNewsListHandler))
 	g.POST("/news/reads", utils.ApiHandle(ns.NewsReadListHandler))
 	g.POST("/news/:origin", utils.ApiHandle(ns.NewsOriginListHandler))
 	g.POST("/news/search", utils.ApiHandle(ns.NewsSearchHandler))
@@ -7,10 +7,16 @@ import (
 	"github.com/golang-queue/queue"
 	"github.com/golang-queue/queue/core"
 	"news/src/config"
+	"news/src/entity"
 	"news/src/logger"
+	"news/src/media"
 	"news/src/models"
 	"news/src/newsaddr"
 	"news/src/storage"
+	"news/src/story"
+	"news/src/summary"
+	"news/src/topic"
+	"news/src/translator"
 	"news/src/utils"
 	"reflect"
 	"strings"
@@ -22,32 +28,90 @@ var s *storage.Service
 
 type pluginFunc func(article *models.Article) error
 
-func translateTitle() pluginFunc {
-	translator, err := utils.NewTranslate(config.Cfg.Kimi.Prompt)
-	if err != nil {
-		panic(err)
+// articleLang 原文语言，根据标题和简介判断，文字太少无法判断时使用来源网站配置的语言
+func articleLang(article *models.Article) string {
+	if article.Lang != "" {
+		return article.Lang
 	}
+	if lang := utils.DetectLang(article.Title, article.Abstract); lang != "" {
+		return lang
+	}
+	if source, ok := config.Cfg.Source[article.From]; ok && source.Lang != "" {
+		return models.NormalizeLang(source.Lang)
+	}
+
+	return models.LangEN
+}
 
+// detectLang 确定原文语言，翻译方向、去重和搜索按原文语言处理
+func detectLang() pluginFunc {
 	return func(article *models.Article) error {
-		// 翻译标题
+		article.Lang = articleLang(article)
+		return nil
+	}
+}
+
+// targetLang 翻译目标语言，中文翻译为英文，其他语言翻译为中文
+func targetLang(lang string) string {
+	if lang == models.LangZH {
+		return models.LangEN
+	}
+
+	return models.LangZH
+}
+
+// translate 翻译单条文本，失败时返回空译文
+func translate(t translator.Translator, target, text string) translator.Result {
+	results, err := translator.Results(context.Background(), t, target, text)
+	if err != nil || len(results) == 0 || results[0].Text == "" {
+		logger.Errorf("Failed to translate %s: %v", text, err)
+		return translator.Result{}
+	}
+
+	return results[0]
+}
+
+// reviewTranslation 译文为空或不符合术语表时标记人工审核，译文为空时记录翻译失败的字段，保存时保留已有译文
+func reviewTranslation(article *models.Article, field string, result translator.Result) {
+	if result.Text == "" {
+		article.Untranslated = append(article.Untranslated, field)
+	}
+	if result.Text == "" || len(result.Violations) > 0 {
+		article.AddReview(field)
+	}
+}
+
+func translateTitle(t translator.Translator) pluginFunc {
+	return func(article *models.Article) error {
+		// 翻译标题，所有译文均未通过校验时标记人工审核
 		if article.Title != "" && article.TitleCN == "" {
-			if article.From == "jinse" || article.From == "bitpie" { // 金色财经和比特派为中文数据
+			if article.Lang == models.LangZH {
 				article.TitleCN = article.Title
-				article.Title, _ = translator.Send(article.Title)
-				article.Title = strings.Split(article.Title, "\n")[0]
+				result := translate(t, models.LangEN, article.Title)
+				if result.Text != "" {
+					article.Title = strings.Split(result.Text, "\n")[0]
+				}
+				reviewTranslation(article, "title", result)
 			} else {
-				article.TitleCN, _ = translator.Send(article.Title)
-				article.TitleCN = strings.Split(article.TitleCN, "\n")[0]
+				result := translate(t, models.LangZH, article.Title)
+				article.TitleCN = strings.Split(result.Text, "\n")[0]
+				reviewTranslation(article, "title_cn", result)
 			}
 		}
 
 		// 翻译简介
-		if article.Abstract != "" && article.AbstractCN == "" && !strings.HasSuffix(article.From, "_coin") {
-			if article.From == "jinse" || article.From == "bitpie" {
+		if article.Abstract != "" && article.AbstractCN == "" && !article.CoinPage {
+			if article.Lang == models.LangZH {
 				article.AbstractCN = article.Abstract
-				article.Abstract, _ = translator.Send(article.Abstract)
+				result := translate(t, models.LangEN, article.Abstract)
+				if result.Text != "" {
+					article.Abstract = result.Text
+				}
+				reviewTranslation(article, "abstract", result)
 			} else {
-				article.AbstractCN, _ = translator.Send(article.Abstract)
+				result := translate(t, models.LangZH, article.Abstract)
+				article.AbstractCN = result.Text
+				reviewTranslation(article, "abstract_cn", result)
 			}
 		}
 
@@ -55,38 +119,154 @@ func translateTitle() pluginFunc {
 	}
 }
 
-func searchImage(ctx context.Context) pluginFunc {
-	g := utils.NewGoogleSearch(ctx)
+func resolveImage(chain *media.Chain, mirror *media.Mirror, placeholders *media.Placeholders) pluginFunc {
 	return func(article *models.Article) error {
-		if article.Image == "" {
-			article.Image, _ = g.Search(article.Title)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		// 转存图片，失效、不合格或占位图片丢弃
+		accept := func(image string) error {
+			article.Image = image
+			err := mirror.Apply(ctx, article)
+			if err == nil && placeholders.Check(article) {
+				err = errors.New("placeholder image")
+			}
+			if err != nil {
+				logger.Infof("Discard image %s of %s: %s", image, article.Link, err)
+				article.Image, article.ImageSource, article.Thumbnail, article.ImageHash = "", "", "", ""
+			}
+			return err
+		}
+
+		// 优先使用来源网站提供的图片，不可用时依次由解析器获取，解析结果同样校验
+		if article.Image != "" && accept(article.Image) == nil {
+			return nil
+		}
+		if _, err := chain.Resolve(article, accept); err != nil {
+			logger.Infof("No image for %s: %s", article.Link, err)
+		}
+
+		return nil
+	}
+}
+
+// mapCategories 将来源网站栏目映射为分类
+func mapCategories(taxonomy *storage.Taxonomy) pluginFunc {
+	return func(article *models.Article) error {
+		if article.CoinPage {
+			return nil
+		}
+
+		category, ok := taxonomy.Map(article.From, article.Category)
+		if !ok {
+			logger.Infof("Skip section %s of %s: %s", article.Category, article.From, article.Link)
+			return errors.New("section skipped")
+		}
+		article.Category = category
+
+		return nil
+	}
+}
+
+// extractEntities 识别标题和简介中的币种、交易所和项目作为文章标签，币种同时关联到文章
+func extractEntities(extractor *entity.Extractor) pluginFunc {
+	return func(article *models.Article) error {
+		for _, e := range extractor.Extract(article.Title, article.TitleCN, article.Abstract, article.AbstractCN) {
+			article.AddTag(e.Slug)
+			if e.Type == entity.CoinType {
+				article.AddCoin(models.Coin{
+					Slug:   e.Slug,
+					Symbol: e.Symbol,
+					Name:   e.Name,
+				})
+			}
 		}
 
 		return nil
 	}
 }
 
-func removeDuplicates(threshold float64) pluginFunc {
+// classifyTopics 文章主题分类
+func classifyTopics(classifier topic.Classifier) pluginFunc {
+	return func(article *models.Article) error {
+		if len(article.Topics) == 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			article.Topics = classifier.Classify(ctx, article)
+		}
+
+		return nil
+	}
+}
+
+// clusterStories 将不同来源网站报道同一事件的文章聚合为事件
+func clusterStories(clusterer *story.Clusterer) pluginFunc {
+	return func(article *models.Article) error {
+		if article.CoinPage {
+			return nil
+		}
+
+		if err := clusterer.Assign(article); err != nil {
+			logger.Errorf("Failed to cluster article %s: %s", article.Link, err)
+		}
+
+		return nil
+	}
+}
+
+// summarize 来源网站没有提供简介时根据文章正文生成中英文简介
+func summarize(summarizer *summary.Summarizer) pluginFunc {
+	return func(article *models.Article) error {
+		if summarizer == nil || article.Abstract != "" || article.CoinPage {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		if err := summarizer.Summarize(ctx, article); err != nil {
+			logger.Warnf("Failed to summarize article %s: %s", article.Link, err)
+		}
+
+		return nil
+	}
+}
+
+// translateLanguages 翻译配置的英文和中文以外的语言，on-demand 配置的语言在接口请求时后台翻译
+func translateLanguages(translations *storage.Translations) pluginFunc {
+	return func(article *models.Article) error {
+		for _, lang := range config.Cfg.Translate.Languages {
+			if err := translations.Apply(lang, article); err != nil {
+				logger.Errorf("Failed to translate article %s to %s: %s", article.Link, lang, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// removeDuplicates 丢弃同一来源网站时间窗口内的重复文章。
+// 跨来源的相似文章不在这里检测，由 clusterStories 聚合为事件
+func removeDuplicates(index *storage.DuplicateIndex) pluginFunc {
 	lock := sync.Mutex{}
-	tm := make(map[string][]string)
 	return func(article *models.Article) error {
+		if article.Title == "" {
+			return nil
+		}
+
 		lock.Lock()
 		defer lock.Unlock()
 
-		key := string(article.Category)
-		titles, ok := tm[key]
-		if !ok {
-			titles = make([]string, 0)
+		article.Token = article.GenToken()
+		if matched, distance, ok := index.Match(article); ok {
+			logger.Infof("Duplicate title: %s, link: %s, matched: %s", article.Title, article.Link, matched)
+			if err := index.Record(article, matched, distance); err != nil {
+				logger.Errorf("Failed to record duplicate article: %s", err)
+			}
+			return errors.New("duplicate title")
 		}
 
-		if article.Title != "" {
-			if utils.IsUniqueStrings(titles, article.Title, threshold) {
-				titles = append(titles, article.Title)
-				tm[key] = titles
-			} else {
-				logger.Infof("Duplicate title: %s, link: %s", article.Title, article.Link)
-				return errors.New("duplicate title")
-			}
+		if err := index.Add(article); err != nil {
+			logger.Errorf("Failed to index article %s: %s", article.Link, err)
 		}
 
 		return nil
@@ -99,10 +279,7 @@ func newQueueWrapper(ctx context.Context, q *queue.Queue) newsaddr.QueueWrapper
 		count = 10
 		ch    = make(chan models.Article, 20)
 	)
-	translator, err := utils.NewTranslate(config.Cfg.Kimi.Prompt)
-	if err != nil {
-		logger.Errorf("Failed to initialize translation: %s", err)
-	}
+	t := translator.New()
 	if config.Cfg.Kimi.Tokens > 0 {
 		count = config.Cfg.Kimi.Tokens
 	}
@@ -134,28 +311,41 @@ func newQueueWrapper(ctx context.Context, q *queue.Queue) newsaddr.QueueWrapper
 					list = list[index:]
 
 					// translate the articles
-					titles := make([]string, len(items))
-					for i, article := range items {
-						titles[i] = article.Title
+					titles := make(map[string][]string)
+					for i := range items {
+						items[i].Lang = articleLang(&items[i])
+						target := targetLang(items[i].Lang)
+						titles[target] = append(titles[target], items[i].Title)
 					}
-					if r, err := translator.Send(titles...); err == nil {
-						if strings.Contains(r, "\n\n") {
-							titles = strings.Split(r, "\n\n")
-						} else {
-							titles = strings.Split(r, "\n")
+					translations := make(map[string][]translator.Result)
+					for target, texts := range titles {
+						r, err := translator.Results(context.Background(), t, target, texts...)
+						if err != nil {
+							logger.Errorf("Failed to translate titles: %s", err)
+						}
+						if len(r) == len(texts) { // 部分翻译失败时保留成功的结果
+							translations[target] = r
 						}
 					}
 
-					for i, article := range items {
-						if i < len(titles) {
-							if article.From == "jinse" || article.From == "bitpie" {
+					offsets := make(map[string]int)
+					for i := range items {
+						article := items[i]
+						target := targetLang(article.Lang)
+						if r, ok := translations[target]; ok && r[offsets[target]].Text != "" {
+							result := r[offsets[target]]
+							if target == models.LangEN {
 								article.TitleCN = article.Title
-								article.Title = titles[i]
+								article.Title = result.Text
+								reviewTranslation(&article, "title", result)
 							} else {
-								article.TitleCN = titles[i]
+								article.TitleCN = result.Text
+								reviewTranslation(&article, "title_cn", result)
 							}
 						}
-						if err = q.Queue(&article); err != nil {
+						offsets[target]++
+
+						if err := q.Queue(&article); err != nil {
 							logger.Errorf("Failed to send article: %s", err)
 						}
 					}
@@ -175,29 +365,6 @@ func newQueueWrapper(ctx context.Context, q *queue.Queue) newsaddr.QueueWrapper
 				continue
 			}
 
-			if article.Image == "" {
-				logger.Infof("starting search for image %s", article.Title)
-				try := 5
-
-			SEARCH:
-				ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-				g := utils.NewGoogleSearch(ctx)
-				article.Image, _ = g.Search(article.Title)
-				g.Close()
-				cancel()
-				if article.Image == "" {
-					if try <= 0 {
-						logger.Infof("Failed to find image for %s", article.Title)
-						continue
-					}
-					logger.Infof("Retrying search for image %s", article.Title)
-					time.Sleep(3 * time.Second)
-					try--
-					goto SEARCH
-				}
-
-				logger.Infof("search result: %s", article.Image)
-			}
 			ch <- article
 		}
 	}
@@ -221,7 +388,7 @@ func newQueue(plugins ...pluginFunc) *queue.Queue {
 		}
 
 		// 保存文章信息
-		if strings.HasSuffix(article.From, "_coin") { // 新闻来源是币
+		if article.CoinPage { // 币种页面的文章
 			if err := s.SaveCoin(article); err != nil {
 				return err
 			}
@@ -237,10 +404,25 @@ func newQueue(plugins ...pluginFunc) *queue.Queue {
 }
 
 func getScrapers(ctx context.Context) ([]newsaddr.Scraper, *queue.Queue) {
-	threshold := config.Cfg.Scrapy.Threshold
+	store := media.NewBlobStore()
+	var summarizer *summary.Summarizer
+	if config.Cfg.Summary.Enable {
+		var err error
+		if summarizer, err = summary.NewSummarizer(); err != nil {
+			logger.Errorf("Failed to initialize summarizer: %s", err)
+		}
+	}
 	q := newQueue(
-		translateTitle(),
-		removeDuplicates(threshold),
+		detectLang(),
+		mapCategories(storage.NewTaxonomy()),
+		translateTitle(translator.New()),
+		removeDuplicates(storage.NewDuplicateIndex()),
+		summarize(summarizer),
+		extractEntities(entity.NewExtractor()),
+		classifyTopics(topic.NewClassifier()),
+		resolveImage(media.DefaultChain(store), media.NewMirror(store), media.NewPlaceholders()),
+		clusterStories(story.NewClusterer()),
+		translateLanguages(storage.NewTranslations(translator.New())),
 	)
 
 	qw := newQueueWrapper(ctx, q)
@@ -263,6 +445,11 @@ func getScrapers(ctx context.Context) ([]newsaddr.Scraper, *queue.Queue) {
 func StartScrapyTask() {
 	logger.Info("Starting task...")
 
+	// 同步迁移后的文章标识到当前版本
+	if err := storage.MigrateTokens(); err != nil {
+		logger.Errorf("Migrating tokens failed: %s", err)
+	}
+
 	// restore articles from storage if exists
 	dataVersion := time.Now().Unix()
 	s = storage.NewServiceWithVersion(dataVersion)
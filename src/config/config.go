@@ -1,6 +1,10 @@
 package config
 
-import "gopkg.in/gcfg.v1"
+import (
+	"gopkg.in/gcfg.v1"
+	"os"
+	"path/filepath"
+)
 
 // config 配置文件结构
 type config struct {
@@ -9,7 +13,7 @@ type config struct {
 		Addr string
 	}
 	Scrapy struct {
-		Threshold float64
+		Threshold float64 // 已废弃，由 Dedup 配置代替
 		Crontab   string
 		UA        string
 	}
@@ -31,6 +35,73 @@ type config struct {
 		Username string
 		Password string
 	}
+	Media struct {
+		Store        string
+		Dir          string
+		URL          string
+		Width        int
+		Height       int
+		MinWidth     int `gcfg:"min-width"`
+		MinHeight    int `gcfg:"min-height"`
+		MaxSize      int `gcfg:"max-size"`
+		Timeout      int
+		Placeholder  int
+		HashDistance int `gcfg:"hash-distance"`
+		Resolver     []string
+	}
+	S3 struct {
+		Endpoint  string
+		Region    string
+		Bucket    string
+		AccessKey string `gcfg:"access-key"`
+		SecretKey string `gcfg:"secret-key"`
+		URL       string
+	}
+	Entity struct {
+		Dictionary string
+	}
+	Topic struct {
+		Mode string
+	}
+	Dedup struct {
+		Window   int
+		Distance int
+	}
+	Story struct {
+		Threshold      float64
+		CrossThreshold float64 `gcfg:"cross-threshold"`
+		Window         int
+	}
+	Logo map[string]*struct {
+		URL string
+	}
+	Source map[string]*struct {
+		Lang string
+	}
+	Translate struct {
+		Backend   []string
+		Timeout   int
+		Version   string
+		Glossary  string
+		Languages []string `gcfg:"language"`
+		OnDemand  []string `gcfg:"on-demand"`
+	}
+	Translator map[string]*struct {
+		Type  string
+		URL   string
+		Key   string
+		Model string
+		Lang  []string
+	}
+	Summary struct {
+		Enable   bool
+		Length   int
+		LengthCN int `gcfg:"length-cn"`
+		Retry    int
+	}
+	Editor map[string]*struct {
+		Key string
+	}
 	Kimi struct {
 		Tokens int
 		Key    string
@@ -42,8 +113,29 @@ var Cfg *config
 
 func init() {
 	Cfg = &config{}
-	err := gcfg.ReadFileInto(Cfg, "config.toml")
+	err := gcfg.ReadFileInto(Cfg, configFile())
 	if err != nil {
 		panic(err)
 	}
 }
+
+// configFile 配置文件路径，当前目录没有时向上级目录查找，便于在子目录中运行测试
+func configFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "config.toml"
+	}
+
+	for {
+		path := filepath.Join(dir, "config.toml")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "config.toml"
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,158 @@
+package entity
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"news/src/config"
+	"news/src/logger"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+//go:embed dictionary.json
+var defaultDictionary []byte
+
+// Type 实体类型
+type Type string
+
+const (
+	// CoinType 币种
+	CoinType Type = "coin"
+
+	// ExchangeType 交易所
+	ExchangeType Type = "exchange"
+
+	// ProjectType 项目、机构
+	ProjectType Type = "project"
+)
+
+// Entity 词典中的实体，strict 为 true 时名称区分大小写，代码只匹配 $ 前缀写法，避免与普通单词混淆
+type Entity struct {
+	Type    Type     `json:"type"`
+	Slug    string   `json:"slug"`
+	Symbol  string   `json:"symbol"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	Strict  bool     `json:"strict"`
+
+	patterns []*regexp.Regexp
+	keywords []string
+}
+
+// compile 生成匹配规则，拉丁字母名称按单词边界匹配，中文名称按子串匹配
+func (e *Entity) compile() error {
+	if e.Slug == "" || e.Name == "" {
+		return fmt.Errorf("invalid entity: %+v", *e)
+	}
+
+	words := make([]string, 0, len(e.Aliases)+1)
+	for _, name := range append([]string{e.Name}, e.Aliases...) {
+		if isHan(name) {
+			e.keywords = append(e.keywords, name)
+		} else {
+			words = append(words, regexp.QuoteMeta(name))
+		}
+	}
+
+	flags := "(?i)"
+	if e.Strict {
+		flags = ""
+	}
+	if len(words) > 0 {
+		e.patterns = append(e.patterns, regexp.MustCompile(flags+`\b(?:`+strings.Join(words, "|")+`)\b`))
+	}
+
+	if e.Symbol != "" {
+		symbol := regexp.QuoteMeta(e.Symbol)
+		e.patterns = append(e.patterns, regexp.MustCompile(`(?i)\$`+symbol+`\b`))
+		if !e.Strict {
+			e.patterns = append(e.patterns, regexp.MustCompile(`\b`+symbol+`\b`))
+		}
+	}
+
+	return nil
+}
+
+// Match 文本中是否包含该实体
+func (e *Entity) Match(text string) bool {
+	for _, keyword := range e.keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	for _, pattern := range e.patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Extractor 根据词典从文本中识别币种、交易所和项目
+type Extractor struct {
+	entities []*Entity
+}
+
+// NewExtractor 加载配置的词典文件，未配置或加载失败时使用内置词典
+func NewExtractor() *Extractor {
+	if path := config.Cfg.Entity.Dictionary; path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var extractor *Extractor
+			if extractor, err = Load(data); err == nil {
+				return extractor
+			}
+		}
+		logger.Errorf("Failed to load dictionary %s, fallback to default: %s", path, err)
+	}
+
+	extractor, err := Load(defaultDictionary)
+	if err != nil {
+		panic(err)
+	}
+
+	return extractor
+}
+
+// Load 从 JSON 数据加载词典
+func Load(data []byte) (*Extractor, error) {
+	entities := make([]*Entity, 0)
+	if err := json.Unmarshal(data, &entities); err != nil {
+		return nil, err
+	}
+
+	for _, e := range entities {
+		if err := e.compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Extractor{entities: entities}, nil
+}
+
+// Extract 识别文本中出现的实体，按词典顺序返回
+func (x *Extractor) Extract(texts ...string) []*Entity {
+	text := strings.Join(texts, "\n")
+	entities := make([]*Entity, 0)
+	for _, e := range x.entities {
+		if e.Match(text) {
+			entities = append(entities, e)
+		}
+	}
+
+	return entities
+}
+
+func isHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -55,6 +55,7 @@ func getLogFile(name string) *os.File {
 		lastLogDate = time.Now()
 	}
 	fileName := fmt.Sprintf("./logs/%s_%s.log", name, currentDate)
+	_ = os.MkdirAll("./logs", 0755)
 
 	logFile, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
@@ -0,0 +1,79 @@
+package media
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache 配图解析结果缓存
+type Cache interface {
+	Get(key string) (string, bool)
+	Set(key, value string)
+	SetTTL(key, value string, ttl time.Duration)
+}
+
+type cacheItem struct {
+	value  string
+	expire time.Time
+}
+
+// MemoryCache 带过期时间的内存缓存
+type MemoryCache struct {
+	items map[string]cacheItem
+	size  int
+	ttl   time.Duration
+	lock  sync.Mutex
+}
+
+func NewMemoryCache(size int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		items: make(map[string]cacheItem),
+		size:  size,
+		ttl:   ttl,
+		lock:  sync.Mutex{},
+	}
+}
+
+func (c *MemoryCache) Get(key string) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(item.expire) {
+		delete(c.items, key)
+		return "", false
+	}
+
+	return item.value, true
+}
+
+func (c *MemoryCache) Set(key, value string) {
+	c.SetTTL(key, value, c.ttl)
+}
+
+// SetTTL 使用指定过期时间写入缓存
+func (c *MemoryCache) SetTTL(key, value string, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// 超出容量时清理过期数据，仍然不足则清空
+	if len(c.items) >= c.size {
+		now := time.Now()
+		for k, item := range c.items {
+			if now.After(item.expire) {
+				delete(c.items, k)
+			}
+		}
+		if len(c.items) >= c.size {
+			c.items = make(map[string]cacheItem)
+		}
+	}
+
+	c.items[key] = cacheItem{
+		value:  value,
+		expire: time.Now().Add(ttl),
+	}
+}
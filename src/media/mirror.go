@@ -0,0 +1,306 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"news/src/config"
+	"news/src/logger"
+	"news/src/models"
+	"strings"
+	"time"
+)
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"image/avif": ".avif",
+}
+
+// undecodableTypes 标准库无法解码的图片格式，从文件头读取尺寸校验后转存原图，不生成缩略图和图片指纹
+var undecodableTypes = map[string]bool{
+	"image/webp": true,
+	"image/avif": true,
+}
+
+// Image 转存后的图片信息
+type Image struct {
+	Source      string
+	URL         string
+	Thumbnail   string
+	Hash        string
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Mirror 下载、校验图片并生成缩略图转存到资源存储
+type Mirror struct {
+	store     BlobStore
+	client    *http.Client
+	cache     *MemoryCache
+	maxSize   int64
+	minWidth  int
+	minHeight int
+	width     int
+	height    int
+}
+
+func NewMirror(store BlobStore) *Mirror {
+	m := config.Cfg.Media
+	mirror := &Mirror{
+		store:     store,
+		client:    &http.Client{Timeout: 60 * time.Second},
+		cache:     NewMemoryCache(10000, 24*time.Hour),
+		maxSize:   int64(m.MaxSize) << 10,
+		minWidth:  m.MinWidth,
+		minHeight: m.MinHeight,
+		width:     m.Width,
+		height:    m.Height,
+	}
+	if mirror.maxSize <= 0 {
+		mirror.maxSize = 10 << 20
+	}
+	if mirror.width <= 0 || mirror.height <= 0 {
+		mirror.width, mirror.height = 640, 360
+	}
+
+	return mirror
+}
+
+// Apply 转存文章配图，成功后替换为稳定的资源地址
+func (m *Mirror) Apply(ctx context.Context, article *models.Article) error {
+	if article.Image == "" {
+		return ErrImageNotFound
+	}
+	if m.store.Owns(article.Image) { // 已经转存
+		if article.Thumbnail == "" {
+			article.Thumbnail = article.Image
+		}
+		return nil
+	}
+
+	img, err := m.Mirror(ctx, article.Image)
+	if err != nil {
+		return err
+	}
+
+	article.ImageSource = img.Source
+	article.Image = img.URL
+	article.Thumbnail = img.Thumbnail
+	article.ImageHash = img.Hash
+	return nil
+}
+
+// Mirror 下载图片并转存原图和缩略图
+func (m *Mirror) Mirror(ctx context.Context, src string) (*Image, error) {
+	key := AssetName(src, "")
+	if url, ok := m.cache.Get(key); ok {
+		thumb, _ := m.cache.Get(key + ":thumb")
+		hash, _ := m.cache.Get(key + ":hash")
+		return &Image{Source: src, URL: url, Thumbnail: thumb, Hash: hash}, nil
+	}
+
+	data, err := m.download(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := m.validate(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image %s: %w", truncate(src, 128), err)
+	}
+	img.Source = src
+
+	// 无法解码的格式只转存原图，缩略图使用原图
+	imageKey := "images/" + key + imageExtensions[img.ContentType]
+	if undecodableTypes[img.ContentType] {
+		if m.store.Exists(ctx, imageKey) {
+			img.URL = m.store.URL(imageKey)
+		} else if img.URL, err = m.store.Put(ctx, imageKey, data, img.ContentType); err != nil {
+			return nil, err
+		}
+		img.Thumbnail = img.URL
+		m.remember(key, img)
+		return img, nil
+	}
+
+	if img.Hash, err = Hash(data); err != nil {
+		return nil, err
+	}
+	thumb, err := Thumbnail(data, m.width, m.height)
+	if err != nil {
+		return nil, err
+	}
+
+	// 资源存储中已存在时不重复上传
+	thumbKey := "thumbs/" + key + ".jpg"
+	if m.store.Exists(ctx, imageKey) && m.store.Exists(ctx, thumbKey) {
+		img.URL, img.Thumbnail = m.store.URL(imageKey), m.store.URL(thumbKey)
+	} else {
+		if img.URL, err = m.store.Put(ctx, imageKey, data, img.ContentType); err != nil {
+			return nil, err
+		}
+		if img.Thumbnail, err = m.store.Put(ctx, thumbKey, thumb, "image/jpeg"); err != nil {
+			return nil, err
+		}
+	}
+
+	m.remember(key, img)
+	return img, nil
+}
+
+// remember 缓存转存结果
+func (m *Mirror) remember(key string, img *Image) {
+	m.cache.Set(key, img.URL)
+	m.cache.Set(key+":thumb", img.Thumbnail)
+	m.cache.Set(key+":hash", img.Hash)
+	logger.Infof("Mirrored image %s to %s", truncate(img.Source, 128), img.URL)
+}
+
+// download 下载图片，支持 data URI
+func (m *Mirror) download(ctx context.Context, src string) ([]byte, error) {
+	if strings.HasPrefix(src, "data:") {
+		parts := strings.SplitN(src, ",", 2)
+		if len(parts) != 2 || !strings.HasSuffix(parts[0], ";base64") {
+			return nil, errors.New("unsupported data uri")
+		}
+		return base64.StdEncoding.DecodeString(parts[1])
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", config.Cfg.Scrapy.UA)
+	req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/*,*/*;q=0.8")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, m.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > m.maxSize {
+		return nil, errors.New("image too large")
+	}
+
+	return data, nil
+}
+
+// validate 校验图片类型和尺寸
+func (m *Mirror) validate(data []byte) (*Image, error) {
+	contentType := detectContentType(data)
+	if _, ok := imageExtensions[contentType]; !ok {
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	cfg, err := decodeConfig(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width < m.minWidth || cfg.Height < m.minHeight {
+		return nil, fmt.Errorf("image too small: %dx%d", cfg.Width, cfg.Height)
+	}
+
+	return &Image{
+		ContentType: contentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+// detectContentType 识别图片类型，补充标准库未识别的 WebP 和 AVIF
+func detectContentType(data []byte) string {
+	if len(data) >= 12 {
+		if string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP" {
+			return "image/webp"
+		}
+		if string(data[4:8]) == "ftyp" && (string(data[8:12]) == "avif" || string(data[8:12]) == "avis") {
+			return "image/avif"
+		}
+	}
+
+	return http.DetectContentType(data)
+}
+
+// decodeConfig 读取图片尺寸，WebP 和 AVIF 从文件头读取
+func decodeConfig(data []byte, contentType string) (image.Config, error) {
+	switch contentType {
+	case "image/webp":
+		return webpConfig(data)
+	case "image/avif":
+		return avifConfig(data)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return cfg, err
+}
+
+// webpConfig 从 VP8、VP8L 或 VP8X 块读取 WebP 尺寸
+func webpConfig(data []byte) (image.Config, error) {
+	if len(data) < 30 {
+		return image.Config{}, errors.New("invalid webp header")
+	}
+
+	cfg := image.Config{}
+	switch string(data[12:16]) {
+	case "VP8 ":
+		if data[23] != 0x9d || data[24] != 0x01 || data[25] != 0x2a {
+			return cfg, errors.New("invalid vp8 frame")
+		}
+		cfg.Width = int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		cfg.Height = int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+	case "VP8L":
+		if data[20] != 0x2f {
+			return cfg, errors.New("invalid vp8l signature")
+		}
+		b := binary.LittleEndian.Uint32(data[21:25])
+		cfg.Width = int(b&0x3fff) + 1
+		cfg.Height = int(b>>14&0x3fff) + 1
+	case "VP8X":
+		cfg.Width = int(uint32(data[24])|uint32(data[25])<<8|uint32(data[26])<<16) + 1
+		cfg.Height = int(uint32(data[27])|uint32(data[28])<<8|uint32(data[29])<<16) + 1
+	default:
+		return cfg, errors.New("unknown webp chunk")
+	}
+
+	return cfg, nil
+}
+
+// avifConfig 从 ispe 属性读取 AVIF 尺寸
+func avifConfig(data []byte) (image.Config, error) {
+	i := bytes.Index(data[:min(len(data), 64<<10)], []byte("ispe"))
+	if i < 0 || i+16 > len(data) {
+		return image.Config{}, errors.New("avif size not found")
+	}
+
+	return image.Config{
+		Width:  int(binary.BigEndian.Uint32(data[i+8 : i+12])),
+		Height: int(binary.BigEndian.Uint32(data[i+12 : i+16])),
+	}, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+
+	return s
+}
@@ -0,0 +1,253 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"io"
+	"net/http"
+	"net/url"
+	"news/src/config"
+	"news/src/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sourceSelectors 各来源网站文章页主图选择器
+var sourceSelectors = map[string][]string{
+	"jinse":      {"div.js-article img", "div.article-main img"},
+	"bitpie":     {"div.entry-content img", "figure.block-image img"},
+	"beincrypto": {"div.featured-images img.bic-featured", "article figure img"},
+	"blockworks": {"article img.object-cover"},
+	"coindesk":   {"div.media > figure > picture > img", "article figure img"},
+	"theblock":   {"div.articleFeatureImage img"},
+	"thedefiant": {"article img.object-cover"},
+	"decrypt":    {"article figure img", "main picture img"},
+	"binance":    {"article img"},
+}
+
+const maxPageSize = 5 << 20
+
+var pageClient = &http.Client{Timeout: 30 * time.Second}
+
+type pagesKey struct{}
+
+// pageResult 页面请求结果
+type pageResult struct {
+	doc *goquery.Document
+	err error
+}
+
+// pageSet 单次解析过程中已请求的页面
+type pageSet struct {
+	pages map[string]pageResult
+	lock  sync.Mutex
+}
+
+// withPages 在上下文中附加页面缓存，同一链接只请求一次
+func withPages(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pagesKey{}, &pageSet{pages: make(map[string]pageResult)})
+}
+
+// fetchDocument 获取并解析页面，上下文带有页面缓存时复用已请求的结果
+func fetchDocument(ctx context.Context, link string) (*goquery.Document, error) {
+	set, ok := ctx.Value(pagesKey{}).(*pageSet)
+	if !ok {
+		return requestDocument(ctx, link)
+	}
+
+	set.lock.Lock()
+	defer set.lock.Unlock()
+	if r, ok := set.pages[link]; ok {
+		return r.doc, r.err
+	}
+
+	doc, err := requestDocument(ctx, link)
+	// 超时或取消的请求不缓存，后续解析器可以重试
+	if ctx.Err() == nil {
+		set.pages[link] = pageResult{doc: doc, err: err}
+	}
+	return doc, err
+}
+
+// requestDocument 请求并解析页面
+func requestDocument(ctx context.Context, link string) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", config.Cfg.Scrapy.UA)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return goquery.NewDocumentFromReader(io.LimitReader(resp.Body, maxPageSize))
+}
+
+// absoluteURL 将页面内的相对地址转换为绝对地址
+func absoluteURL(base, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+
+	return b.ResolveReference(r).String()
+}
+
+// findImage 按选择器顺序查找图片地址
+func findImage(doc *goquery.Document, base string, selectors ...string) string {
+	for _, sel := range selectors {
+		var image string
+		doc.Find(sel).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			for _, attr := range []string{"content", "href", "src", "data-src"} {
+				if v, ok := s.Attr(attr); ok {
+					if image = absoluteURL(base, v); image != "" {
+						return false
+					}
+				}
+			}
+			return true
+		})
+		if image != "" {
+			return image
+		}
+	}
+
+	return ""
+}
+
+// OpenGraphResolver 读取文章页面的 og:image
+type OpenGraphResolver struct{}
+
+func NewOpenGraphResolver() *OpenGraphResolver {
+	return &OpenGraphResolver{}
+}
+
+func (r *OpenGraphResolver) Name() string {
+	return "opengraph"
+}
+
+func (r *OpenGraphResolver) Resolve(ctx context.Context, article *models.Article) (string, error) {
+	if article.Link == "" {
+		return "", ErrImageNotFound
+	}
+
+	doc, err := fetchDocument(ctx, article.Link)
+	if err != nil {
+		return "", err
+	}
+
+	image := findImage(doc, article.Link,
+		"meta[property='og:image']",
+		"meta[property='og:image:url']",
+		"meta[name='twitter:image']",
+		"link[rel='image_src']",
+	)
+	if image == "" {
+		return "", ErrImageNotFound
+	}
+
+	return image, nil
+}
+
+// SourceResolver 根据来源网站的页面结构查找文章主图
+type SourceResolver struct {
+	selectors map[string][]string
+}
+
+func NewSourceResolver() *SourceResolver {
+	return &SourceResolver{
+		selectors: sourceSelectors,
+	}
+}
+
+func (r *SourceResolver) Name() string {
+	return "source"
+}
+
+func (r *SourceResolver) Resolve(ctx context.Context, article *models.Article) (string, error) {
+	selectors, ok := r.selectors[article.From]
+	if !ok || article.Link == "" {
+		return "", ErrImageNotFound
+	}
+
+	doc, err := fetchDocument(ctx, article.Link)
+	if err != nil {
+		return "", err
+	}
+
+	image := findImage(doc, article.Link, selectors...)
+	if image == "" {
+		return "", ErrImageNotFound
+	}
+
+	return image, nil
+}
+
+// LogoResolver 使用来源网站的默认Logo
+type LogoResolver struct {
+	logos sync.Map
+}
+
+func NewLogoResolver() *LogoResolver {
+	r := &LogoResolver{}
+	for source, logo := range config.Cfg.Logo {
+		if logo != nil && logo.URL != "" {
+			r.logos.Store(source, logo.URL)
+		}
+	}
+
+	return r
+}
+
+func (r *LogoResolver) Name() string {
+	return "logo"
+}
+
+func (r *LogoResolver) Resolve(ctx context.Context, article *models.Article) (string, error) {
+	if logo, ok := r.logos.Load(article.From); ok {
+		return logo.(string), nil
+	}
+
+	// 未配置时读取网站首页声明的图标
+	u, err := url.Parse(article.Link)
+	if err != nil || u.Host == "" {
+		return "", ErrImageNotFound
+	}
+	home := fmt.Sprintf("%s://%s/", u.Scheme, u.Host)
+
+	doc, err := fetchDocument(ctx, home)
+	if err != nil {
+		return "", err
+	}
+
+	logo := findImage(doc, home,
+		"meta[property='og:image']",
+		"link[rel='apple-touch-icon']",
+		"link[rel='icon'][sizes]",
+	)
+	if logo == "" {
+		return "", ErrImageNotFound
+	}
+
+	r.logos.Store(article.From, logo)
+	return logo, nil
+}
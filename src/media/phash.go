@@ -0,0 +1,54 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+)
+
+// Hash 计算图片的感知哈希（dHash），缩放为9x8灰度图后比较相邻像素亮度
+func Hash(data []byte) (string, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	return HashImage(src), nil
+}
+
+func HashImage(src image.Image) string {
+	img := Resize(src, 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if luminance(img.RGBAAt(x, y).R, img.RGBAAt(x, y).G, img.RGBAAt(x, y).B) >
+				luminance(img.RGBAAt(x+1, y).R, img.RGBAAt(x+1, y).G, img.RGBAAt(x+1, y).B) {
+				hash |= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%016x", hash)
+}
+
+// HashDistance 计算两个感知哈希的汉明距离，无法比较时返回-1
+func HashDistance(a, b string) int {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return -1
+	}
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return -1
+	}
+
+	return bits.OnesCount64(x ^ y)
+}
+
+func luminance(r, g, b uint8) float64 {
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+}
@@ -0,0 +1,143 @@
+package media
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"news/src/config"
+	"news/src/logger"
+	"news/src/models"
+	"sync"
+	"time"
+)
+
+// Placeholders 占位图检测，同一来源多篇文章共用的图片（站点Logo、通用横幅等）视为占位图
+type Placeholders struct {
+	db        *gorm.DB
+	threshold int
+	distance  int
+
+	seen    map[string]map[string]map[string]struct{} // source -> hash -> links
+	flagged map[string][]string                       // source -> hashes
+	loaded  map[string]time.Time
+	lock    sync.Mutex
+}
+
+func NewPlaceholders() *Placeholders {
+	m := config.Cfg.Media
+	p := &Placeholders{
+		db:        models.DB,
+		threshold: m.Placeholder,
+		distance:  m.HashDistance,
+		seen:      make(map[string]map[string]map[string]struct{}),
+		flagged:   make(map[string][]string),
+		loaded:    make(map[string]time.Time),
+	}
+	if p.threshold <= 0 {
+		p.threshold = 5
+	}
+	if p.distance < 0 {
+		p.distance = 0
+	}
+
+	return p
+}
+
+// Check 记录文章图片并判断是否为占位图
+func (p *Placeholders) Check(article *models.Article) bool {
+	if article.ImageHash == "" {
+		return false
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.load(article.From)
+
+	hashes, ok := p.seen[article.From]
+	if !ok {
+		hashes = make(map[string]map[string]struct{})
+		p.seen[article.From] = hashes
+	}
+	links, ok := hashes[article.ImageHash]
+	if !ok {
+		links = make(map[string]struct{})
+		hashes[article.ImageHash] = links
+	}
+	links[article.Link] = struct{}{}
+
+	if len(links) >= p.threshold {
+		p.flag(article.From, article.ImageHash, len(links))
+	}
+
+	for _, hash := range p.flagged[article.From] {
+		if d := HashDistance(hash, article.ImageHash); d >= 0 && d <= p.distance {
+			return true
+		}
+	}
+
+	return false
+}
+
+// load 加载来源网站已记录的占位图和图片使用次数
+func (p *Placeholders) load(source string) {
+	if t, ok := p.loaded[source]; ok && time.Since(t) < time.Hour {
+		return
+	}
+	p.loaded[source] = time.Now()
+
+	placeholders := make([]models.ImagePlaceholder, 0)
+	if err := p.db.Where("`from` = ?", source).Find(&placeholders).Error; err != nil {
+		logger.Errorf("Failed to load image placeholders: %s", err)
+		return
+	}
+
+	hashes := make([]string, 0, len(placeholders))
+	for _, item := range placeholders {
+		hashes = append(hashes, item.Hash)
+	}
+	p.flagged[source] = hashes
+
+	rows := make([]struct {
+		ImageHash string
+		Count     int
+	}, 0)
+	err := p.db.Model(&models.Article{}).
+		Select("image_hash, COUNT(DISTINCT link) AS count").
+		Where("`from` = ? AND image_hash != ''", source).
+		Group("image_hash").
+		Having("COUNT(DISTINCT link) >= ?", p.threshold).
+		Scan(&rows).Error
+	if err != nil {
+		logger.Errorf("Failed to count image hashes: %s", err)
+		return
+	}
+
+	for _, row := range rows {
+		p.flag(source, row.ImageHash, row.Count)
+	}
+}
+
+// flag 标记占位图
+func (p *Placeholders) flag(source, hash string, count int) {
+	for _, h := range p.flagged[source] {
+		if h == hash {
+			return
+		}
+	}
+	p.flagged[source] = append(p.flagged[source], hash)
+
+	logger.Infof("Flagged placeholder image of %s: %s, used by %d articles", source, hash, count)
+	err := p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "from"}, {Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"count", "update_time"}),
+	}).Create(&models.ImagePlaceholder{
+		From:       source,
+		Hash:       hash,
+		Count:      count,
+		CreateTime: time.Now(),
+		UpdateTime: time.Now(),
+	}).Error
+	if err != nil {
+		logger.Errorf("Failed to save image placeholder: %s", err)
+	}
+}
@@ -0,0 +1,167 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"news/src/config"
+	"news/src/logger"
+	"news/src/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrImageNotFound = errors.New("image not found")
+
+// Resolver 文章配图解析器
+type Resolver interface {
+	Name() string
+	Resolve(ctx context.Context, article *models.Article) (string, error)
+}
+
+// Chain 按顺序执行的配图解析链，任一解析器的结果通过校验即返回
+type Chain struct {
+	resolvers []Resolver
+	timeout   time.Duration
+	cache     Cache
+}
+
+func NewChain(timeout time.Duration, cache Cache, resolvers ...Resolver) *Chain {
+	return &Chain{
+		resolvers: resolvers,
+		timeout:   timeout,
+		cache:     cache,
+	}
+}
+
+var (
+	defaultChain     *Chain
+	defaultChainOnce sync.Once
+)
+
+// DefaultChain 进程内共用的解析链，解析结果缓存在每轮抓取任务之间保留
+func DefaultChain(store BlobStore) *Chain {
+	defaultChainOnce.Do(func() {
+		defaultChain = NewDefaultChain(store)
+	})
+
+	return defaultChain
+}
+
+// NewDefaultChain 根据配置创建解析链
+func NewDefaultChain(store BlobStore) *Chain {
+	m := config.Cfg.Media
+	timeout := time.Duration(m.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	names := m.Resolver
+	if len(names) == 0 {
+		names = []string{"opengraph", "source", "screenshot", "logo", "search"}
+	}
+
+	resolvers := make([]Resolver, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "opengraph":
+			resolvers = append(resolvers, NewOpenGraphResolver())
+		case "source":
+			resolvers = append(resolvers, NewSourceResolver())
+		case "screenshot":
+			resolvers = append(resolvers, NewScreenshotResolver(store, m.Width, m.Height))
+		case "logo":
+			resolvers = append(resolvers, NewLogoResolver())
+		case "search":
+			resolvers = append(resolvers, NewSearchResolver())
+		default:
+			logger.Warnf("Unknown image resolver: %s", name)
+		}
+	}
+
+	return NewChain(timeout, NewMemoryCache(10000, 24*time.Hour), resolvers...)
+}
+
+// missTTL 解析失败结果的缓存时间，避免每轮任务重复搜索
+const missTTL = 2 * time.Hour
+
+// Accept 校验解析得到的配图，返回错误时丢弃并继续执行下一个解析器
+type Accept func(image string) error
+
+// Resolve 依次执行解析器获取文章配图，accept 为空时使用第一个解析结果
+func (c *Chain) Resolve(article *models.Article, accept Accept) (string, error) {
+	if accept == nil {
+		accept = func(string) error { return nil }
+	}
+
+	// 先按链接查找，再按标题查找其他来源相同标题的解析结果，只有链接缓存解析失败的结果
+	key, title := cacheKey(article), titleKey(article)
+	missed := false
+	if c.cache != nil {
+		for _, k := range []string{key, title} {
+			if k == "" {
+				continue
+			}
+			if image, ok := c.cache.Get(k); ok {
+				if image == "" {
+					missed = true
+				} else if accept(image) == nil {
+					return image, nil
+				}
+			}
+		}
+	}
+	if missed {
+		return "", ErrImageNotFound
+	}
+
+	// 同一篇文章的页面只请求一次，由各解析器共享
+	base := withPages(context.Background())
+	for _, r := range c.resolvers {
+		ctx, cancel := context.WithTimeout(base, c.timeout)
+		image, err := r.Resolve(ctx, article)
+		cancel()
+		if err != nil || image == "" {
+			logger.Debugf("[%s]Failed to resolve image for %s: %v", r.Name(), article.Link, err)
+			continue
+		}
+		if err = accept(image); err != nil {
+			logger.Infof("[%s]Discard image %s for %s: %s", r.Name(), image, article.Link, err)
+			continue
+		}
+
+		logger.Infof("[%s]Resolved image for %s: %s", r.Name(), article.Title, image)
+		if c.cache != nil {
+			for _, k := range []string{key, title} {
+				if k != "" {
+					c.cache.Set(k, image)
+				}
+			}
+		}
+		return image, nil
+	}
+
+	if c.cache != nil && key != "" {
+		c.cache.SetTTL(key, "", missTTL)
+	}
+	return "", ErrImageNotFound
+}
+
+// cacheKey 解析结果缓存键，优先使用文章链接
+func cacheKey(article *models.Article) string {
+	if article.Link != "" {
+		return article.Link
+	}
+
+	return titleKey(article)
+}
+
+// titleKey 按标题缓存的键，忽略大小写和多余空白
+func titleKey(article *models.Article) string {
+	title := strings.Join(strings.Fields(strings.ToLower(article.Title)), " ")
+	if title == "" {
+		return ""
+	}
+
+	return "title:" + title
+}
@@ -0,0 +1,109 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"github.com/chromedp/chromedp"
+	"news/src/logger"
+	"news/src/utils"
+	"sync"
+	"time"
+)
+
+// heroSelectors 文章主图区域选择器，按优先级排列
+var heroSelectors = []string{
+	"article figure img",
+	"article header img",
+	"article img",
+	"main figure img",
+	"header figure img",
+}
+
+// heroAttr 标记已选中主图的属性
+const heroAttr = "data-news-hero"
+
+// Screenshot 基于浏览器截图生成文章缩略图
+type Screenshot struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	store  BlobStore
+	width  int
+	height int
+	lock   sync.Mutex
+}
+
+func NewScreenshot(c context.Context, store BlobStore, width, height int) *Screenshot {
+	ctx, cancel := utils.NewBrowserContext(c)
+	return &Screenshot{
+		ctx:    ctx,
+		cancel: cancel,
+		store:  store,
+		width:  width,
+		height: height,
+		lock:   sync.Mutex{},
+	}
+}
+
+// Capture 截取文章页面主图区域，找不到主图时截取页面顶部
+func (s *Screenshot) Capture(link string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if link == "" {
+		return nil, errors.New("empty link")
+	}
+
+	var buf []byte
+	err := chromedp.Run(s.ctx,
+		chromedp.EmulateViewport(1280, 800),
+		chromedp.Navigate(link),
+		chromedp.WaitReady("body", chromedp.ByQuery),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			for _, sel := range heroSelectors {
+				// 标记第一张尺寸足够的图片，只截取该元素
+				var found bool
+				js := "(() => { document.querySelectorAll('[" + heroAttr + "]').forEach(e => e.removeAttribute('" + heroAttr + "')); const e = Array.from(document.querySelectorAll('" + sel + "')).find(e => e.naturalWidth >= 320 && e.naturalHeight >= 180); if (!e) return false; e.setAttribute('" + heroAttr + "', '1'); return true; })()"
+				if err := chromedp.Evaluate(js, &found).Do(ctx); err != nil || !found {
+					continue
+				}
+
+				c, cancel := context.WithTimeout(ctx, 10*time.Second)
+				err := chromedp.Screenshot("["+heroAttr+"]", &buf, chromedp.ByQuery, chromedp.NodeVisible).Do(c)
+				cancel()
+				if err == nil && len(buf) > 0 {
+					logger.Infof("Captured hero image '%s' of %s", sel, link)
+					return nil
+				}
+			}
+
+			// 页面顶部
+			return chromedp.CaptureScreenshot(&buf).Do(ctx)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// Thumbnail 截图并生成缩略图，返回资源访问地址
+func (s *Screenshot) Thumbnail(link string) (string, error) {
+	buf, err := s.Capture(link)
+	if err != nil {
+		logger.Errorf("Failed to capture screenshot of %s: %s", link, err)
+		return "", err
+	}
+
+	thumb, err := Thumbnail(buf, s.width, s.height)
+	if err != nil {
+		logger.Errorf("Failed to generate thumbnail of %s: %s", link, err)
+		return "", err
+	}
+
+	return s.store.Put(s.ctx, "screenshots/"+AssetName(link, ".jpg"), thumb, "image/jpeg")
+}
+
+func (s *Screenshot) Close() {
+	s.cancel()
+}
@@ -0,0 +1,64 @@
+package media
+
+import (
+	"context"
+	"news/src/models"
+	"news/src/utils"
+)
+
+// SearchResolver 通过谷歌图片搜索文章配图
+type SearchResolver struct{}
+
+func NewSearchResolver() *SearchResolver {
+	return &SearchResolver{}
+}
+
+func (r *SearchResolver) Name() string {
+	return "search"
+}
+
+func (r *SearchResolver) Resolve(ctx context.Context, article *models.Article) (string, error) {
+	if article.Title == "" {
+		return "", ErrImageNotFound
+	}
+
+	g := utils.NewGoogleSearch(ctx)
+	defer g.Close()
+
+	image, ok := g.Search(article.Title)
+	if !ok || image == "" {
+		return "", ErrImageNotFound
+	}
+
+	return image, nil
+}
+
+// ScreenshotResolver 截取文章页面生成缩略图
+type ScreenshotResolver struct {
+	store  BlobStore
+	width  int
+	height int
+}
+
+func NewScreenshotResolver(store BlobStore, width, height int) *ScreenshotResolver {
+	return &ScreenshotResolver{
+		store:  store,
+		width:  width,
+		height: height,
+	}
+}
+
+func (r *ScreenshotResolver) Name() string {
+	return "screenshot"
+}
+
+func (r *ScreenshotResolver) Resolve(ctx context.Context, article *models.Article) (string, error) {
+	if article.Link == "" {
+		return "", ErrImageNotFound
+	}
+
+	shot := NewScreenshot(ctx, r.store, r.width, r.height)
+	defer shot.Close()
+
+	return shot.Thumbnail(article.Link)
+}
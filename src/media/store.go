@@ -0,0 +1,104 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"news/src/config"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore 图片资源存储
+type BlobStore interface {
+	Name() string
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	Exists(ctx context.Context, key string) bool
+	URL(key string) string
+	Owns(url string) bool
+}
+
+// NewBlobStore 根据配置创建资源存储
+func NewBlobStore() BlobStore {
+	if config.Cfg.Media.Store == "s3" {
+		return NewS3Store()
+	}
+
+	return NewLocalStore()
+}
+
+// LocalStore 本地磁盘资源存储，通过API静态资源路由访问
+type LocalStore struct {
+	dir string
+	url string
+}
+
+func NewLocalStore() *LocalStore {
+	dir, url := localPaths()
+	return &LocalStore{
+		dir: dir,
+		url: url,
+	}
+}
+
+// localPaths 本地存储目录与访问路径，未配置时使用默认值
+func localPaths() (string, string) {
+	m := config.Cfg.Media
+	dir, url := m.Dir, strings.TrimSuffix(m.URL, "/")
+	if dir == "" {
+		dir = "./assets"
+	}
+	if url == "" {
+		url = "/assets"
+	}
+
+	return dir, url
+}
+
+// StaticRoute 本地存储的静态资源路由，非本地存储时返回 false
+func StaticRoute() (string, string, bool) {
+	if config.Cfg.Media.Store == "s3" {
+		return "", "", false
+	}
+
+	dir, url := localPaths()
+	return url, dir, true
+}
+
+func (s *LocalStore) Name() string {
+	return "local"
+}
+
+// Put 保存资源文件，返回访问地址
+func (s *LocalStore) Put(_ context.Context, key string, data []byte, _ string) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+func (s *LocalStore) Exists(_ context.Context, key string) bool {
+	_, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(key)))
+	return err == nil
+}
+
+func (s *LocalStore) URL(key string) string {
+	return s.url + "/" + key
+}
+
+func (s *LocalStore) Owns(url string) bool {
+	return strings.HasPrefix(url, s.url+"/")
+}
+
+// AssetName 根据来源地址生成资源文件名
+func AssetName(key, ext string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:16]) + ext
+}
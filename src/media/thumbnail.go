@@ -0,0 +1,115 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	_ "image/gif"
+	_ "image/png"
+)
+
+// Thumbnail 按目标宽高比裁剪图片（保留顶部），缩放后编码为JPEG
+func Thumbnail(data []byte, width, height int) ([]byte, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("invalid thumbnail size")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	dst := Resize(Crop(src, width, height), width, height)
+
+	buf := &bytes.Buffer{}
+	if err = jpeg.Encode(buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Crop 按宽高比裁剪，水平居中，垂直方向保留顶部
+func Crop(src image.Image, width, height int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w*height > h*width { // 过宽
+		cw := h * width / height
+		x := b.Min.X + (w-cw)/2
+		return subImage(src, image.Rect(x, b.Min.Y, x+cw, b.Max.Y))
+	}
+
+	ch := w * height / width
+	return subImage(src, image.Rect(b.Min.X, b.Min.Y, b.Max.X, b.Min.Y+ch))
+}
+
+// Resize 双线性插值缩放
+func Resize(src image.Image, width, height int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	sx := float64(b.Dx()) / float64(width)
+	sy := float64(b.Dy()) / float64(height)
+
+	for y := 0; y < height; y++ {
+		fy := (float64(y)+0.5)*sy - 0.5
+		y0 := clamp(int(fy), 0, b.Dy()-1)
+		y1 := clamp(y0+1, 0, b.Dy()-1)
+		dy := fy - float64(y0)
+		if dy < 0 {
+			dy = 0
+		}
+
+		for x := 0; x < width; x++ {
+			fx := (float64(x)+0.5)*sx - 0.5
+			x0 := clamp(int(fx), 0, b.Dx()-1)
+			x1 := clamp(x0+1, 0, b.Dx()-1)
+			dx := fx - float64(x0)
+			if dx < 0 {
+				dx = 0
+			}
+
+			c00 := color.RGBA64Model.Convert(src.At(b.Min.X+x0, b.Min.Y+y0)).(color.RGBA64)
+			c10 := color.RGBA64Model.Convert(src.At(b.Min.X+x1, b.Min.Y+y0)).(color.RGBA64)
+			c01 := color.RGBA64Model.Convert(src.At(b.Min.X+x0, b.Min.Y+y1)).(color.RGBA64)
+			c11 := color.RGBA64Model.Convert(src.At(b.Min.X+x1, b.Min.Y+y1)).(color.RGBA64)
+
+			lerp := func(a, b, c, d uint16) uint8 {
+				top := float64(a)*(1-dx) + float64(b)*dx
+				bottom := float64(c)*(1-dx) + float64(d)*dx
+				return uint8((top*(1-dy) + bottom*dy) / 257)
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: lerp(c00.R, c10.R, c01.R, c11.R),
+				G: lerp(c00.G, c10.G, c01.G, c11.G),
+				B: lerp(c00.B, c10.B, c01.B, c11.B),
+				A: lerp(c00.A, c10.A, c01.A, c11.A),
+			})
+		}
+	}
+
+	return dst
+}
+
+func subImage(src image.Image, r image.Rectangle) image.Image {
+	if s, ok := src.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+
+	return src
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+
+	return v
+}
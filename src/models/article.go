@@ -6,29 +6,56 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"news/src/utils"
+	"slices"
 	"time"
 )
 
-// Article 文章信息
+const (
+	// LangEN 英文
+	LangEN = "en"
+
+	// LangZH 中文
+	LangZH = "zh"
+)
+
+// Article 文章信息，Lang 为来源网站原文语言，Title 为英文标题，TitleCN 为中文标题
 type Article struct {
-	ID           int           `gorm:"column:id;primaryKey" json:"id"`
-	Token        string        `gorm:"column:token;size:256;index:idx_token" json:"token"`
-	From         string        `gorm:"column:from;size:64;idx_from" json:"from"`
-	Title        string        `gorm:"column:title;size:256;index:idx_title;not null" json:"title"`
-	TitleCN      string        `gorm:"column:title_ch;size:256" json:"title_cn"`
-	Abstract     string        `gorm:"column:abstract;type:text" json:"abstract"`
-	AbstractCN   string        `gorm:"column:abstract_ch;type:text" json:"abstract_cn"`
-	Image        string        `gorm:"column:image;size:512" json:"image"`
-	Link         string        `gorm:"column:link;size:512" json:"link"`
-	PubDate      sql.NullTime  `gorm:"column:pub_date" json:"pub_date"`
-	Author       string        `gorm:"column:author;size:64" json:"author"`
-	Category     CategoryTypes `gorm:"column:category;size:64;index:idx_category" json:"category"`
-	Reads        int           `gorm:"column:reads" json:"reads"`
-	Interactions int           `gorm:"column:interactions" json:"interactions"`
-	Comments     int           `gorm:"column:comments" json:"comments"`
-	Notes        string        `gorm:"column:notes;size:256" json:"notes"`
-	CreateTime   time.Time     `gorm:"column:create_time" json:"create_time"`
-	UpdateTime   time.Time     `gorm:"column:update_time" json:"update_time"`
+	ID                int                            `gorm:"column:id;primaryKey" json:"id"`
+	Token             string                         `gorm:"column:token;size:256;index:idx_token" json:"token"`
+	LegacyToken       string                         `gorm:"column:legacy_token;size:256;index:idx_legacy_token" json:"-"`
+	From              string                         `gorm:"column:from;size:64;idx_from" json:"from"`
+	Lang              string                         `gorm:"column:lang;size:16" json:"lang"`
+	Title             string                         `gorm:"column:title;size:256;index:idx_title;not null" json:"title"`
+	TitleCN           string                         `gorm:"column:title_ch;size:256" json:"title_cn"`
+	Abstract          string                         `gorm:"column:abstract;type:text" json:"abstract"`
+	AbstractCN        string                         `gorm:"column:abstract_ch;type:text" json:"abstract_cn"`
+	AbstractGenerated bool                           `gorm:"column:abstract_generated" json:"abstract_generated"`
+	Image             string                         `gorm:"column:image;size:512" json:"image"`
+	ImageSource       string                         `gorm:"column:image_source;type:text" json:"image_source"`
+	Thumbnail         string                         `gorm:"column:thumbnail;size:512" json:"thumbnail"`
+	ImageHash         string                         `gorm:"column:image_hash;size:16;index:idx_image_hash" json:"image_hash"`
+	Link              string                         `gorm:"column:link;size:512" json:"link"`
+	CanonicalURL      string                         `gorm:"column:canonical_url;size:512" json:"canonical_url"`
+	PubDate           sql.NullTime                   `gorm:"column:pub_date" json:"pub_date"`
+	Author            string                         `gorm:"column:author;size:64" json:"author"`
+	Category          CategoryTypes                  `gorm:"column:category;size:64;index:idx_category" json:"category"`
+	Categories        []CategoryTypes                `gorm:"-" json:"categories"`
+	Coins             []Coin                         `gorm:"-" json:"coins"`
+	CoinPage          bool                           `gorm:"-" json:"coin_page,omitempty"`
+	Tags              []string                       `gorm:"column:tags;serializer:json;type:text" json:"tags"`
+	Topics            []string                       `gorm:"column:topics;serializer:json;type:text" json:"topics"`
+	Story             string                         `gorm:"column:story;size:256;index:idx_story" json:"story"`
+	Review            []string                       `gorm:"column:review;serializer:json;type:text" json:"review"`
+	Locked            []string                       `gorm:"column:locked;serializer:json;type:text" json:"locked"`
+	Translations      map[string]*ArticleTranslation `gorm:"-" json:"-"`
+	Untranslated      []string                       `gorm:"-" json:"-"` // 本次翻译失败的字段，中文原文时字段仍为中文
+	Reads             int                            `gorm:"column:reads" json:"reads"`
+	Interactions      int                            `gorm:"column:interactions" json:"interactions"`
+	Comments          int                            `gorm:"column:comments" json:"comments"`
+	Notes             string                         `gorm:"column:notes;size:256" json:"notes"`
+	CreateTime        time.Time                      `gorm:"column:create_time" json:"create_time"`
+	UpdateTime        time.Time                      `gorm:"column:update_time" json:"update_time"`
 }
 
 func (a *Article) TableName() string {
@@ -52,32 +79,194 @@ func (a *Article) Bytes() []byte {
 	return b
 }
 
+// GenToken 根据来源和规范化地址生成文章标识，没有链接时使用标题
 func (a *Article) GenToken() string {
 	if a.Token != "" {
 		return a.Token
 	}
 
-	data := fmt.Sprintf("%s", a.Title)
+	if a.CanonicalURL == "" {
+		a.CanonicalURL = utils.CanonicalURL(a.Link)
+	}
+	if a.CanonicalURL == "" {
+		return a.GenLegacyToken()
+	}
+
+	data := fmt.Sprintf("%s|%s", a.From, a.CanonicalURL)
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:])
 }
 
+// GenLegacyToken 旧版本根据标题生成的文章标识
+func (a *Article) GenLegacyToken() string {
+	hash := sha256.Sum256([]byte(a.Title))
+	return hex.EncodeToString(hash[:])
+}
+
+// GetTitleByLang 指定语言的标题，没有译文时使用英文标题
 func (a *Article) GetTitleByLang(lang string) string {
-	if lang == "ch" && a.TitleCN != "" {
-		return a.TitleCN
+	switch lang = NormalizeLang(lang); {
+	case lang == LangEN:
+	case IsChinese(lang):
+		if a.TitleCN != "" {
+			return ConvertChinese(a.TitleCN, lang)
+		}
+	default:
+		if t, ok := a.Translations[lang]; ok && t.Title != "" {
+			return t.Title
+		}
 	}
 
 	return a.Title
 }
 
+// GetAbstractByLang 指定语言的简介，没有译文时使用英文简介
 func (a *Article) GetAbstractByLang(lang string) string {
-	if lang == "ch" && a.AbstractCN != "" {
-		return a.AbstractCN
+	switch lang = NormalizeLang(lang); {
+	case lang == LangEN:
+	case IsChinese(lang):
+		if a.AbstractCN != "" {
+			return ConvertChinese(a.AbstractCN, lang)
+		}
+	default:
+		if t, ok := a.Translations[lang]; ok && t.Abstract != "" {
+			return t.Abstract
+		}
 	}
 
 	return a.Abstract
 }
 
+// SetTranslation 设置英文和中文以外的译文
+func (a *Article) SetTranslation(t *ArticleTranslation) {
+	if a.Translations == nil {
+		a.Translations = make(map[string]*ArticleTranslation)
+	}
+	a.Translations[t.Lang] = t
+}
+
+// AddCategory 添加文章所属分类
+func (a *Article) AddCategory(categories ...CategoryTypes) {
+	for _, category := range categories {
+		if category == "" || a.HasCategory(category) {
+			continue
+		}
+		a.Categories = append(a.Categories, category)
+	}
+
+	if a.Category == "" && len(a.Categories) > 0 {
+		a.Category = a.Categories[0]
+	}
+}
+
+// HasCategory 文章是否属于指定分类
+func (a *Article) HasCategory(category CategoryTypes) bool {
+	for _, c := range a.Categories {
+		if c == category {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddCoin 添加文章关联的币种，已存在时补全币种信息
+func (a *Article) AddCoin(coins ...Coin) {
+	for _, coin := range coins {
+		if coin.Slug == "" {
+			continue
+		}
+
+		exists := false
+		for i := range a.Coins {
+			if a.Coins[i].Slug != coin.Slug {
+				continue
+			}
+			exists = true
+			a.Coins[i].Merge(&coin)
+		}
+		if !exists {
+			coin.ID = 0
+			a.Coins = append(a.Coins, coin)
+		}
+	}
+}
+
+// AddTag 添加文章标签
+func (a *Article) AddTag(tags ...string) {
+	for _, tag := range tags {
+		if tag == "" || slices.Contains(a.Tags, tag) {
+			continue
+		}
+		a.Tags = append(a.Tags, tag)
+	}
+}
+
+// AddReview 标记需要人工审核的字段，如所有翻译服务的译文均未通过校验
+func (a *Article) AddReview(fields ...string) {
+	for _, field := range fields {
+		if field == "" || slices.Contains(a.Review, field) {
+			continue
+		}
+		a.Review = append(a.Review, field)
+	}
+}
+
+// RemoveReview 字段已由编辑修改，不再需要审核
+func (a *Article) RemoveReview(field string) {
+	a.Review = slices.DeleteFunc(a.Review, func(f string) bool {
+		return f == field
+	})
+}
+
+// GetField 获取编辑可以修改的字段
+func (a *Article) GetField(field string) string {
+	switch field {
+	case FieldTitle:
+		return a.Title
+	case FieldTitleCN:
+		return a.TitleCN
+	case FieldAbstract:
+		return a.Abstract
+	case FieldAbstractCN:
+		return a.AbstractCN
+	}
+
+	return ""
+}
+
+// SetField 修改编辑可以修改的字段，字段不可修改时返回 false
+func (a *Article) SetField(field, value string) bool {
+	switch field {
+	case FieldTitle:
+		a.Title = value
+	case FieldTitleCN:
+		a.TitleCN = value
+	case FieldAbstract:
+		a.Abstract = value
+	case FieldAbstractCN:
+		a.AbstractCN = value
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Lock 锁定编辑修改的字段，抓取任务不再覆盖
+func (a *Article) Lock(field string) {
+	if !slices.Contains(a.Locked, field) {
+		a.Locked = append(a.Locked, field)
+	}
+}
+
+// Unlock 解除字段锁定
+func (a *Article) Unlock(field string) {
+	a.Locked = slices.DeleteFunc(a.Locked, func(f string) bool {
+		return f == field
+	})
+}
+
 func (a *Article) GetScore() float64 {
 	if a.PubDate.Valid {
 		return float64(a.PubDate.Time.Unix())
@@ -1,6 +1,8 @@
 package models
 
-// CategoryTypes 分类类型
+import "time"
+
+// CategoryTypes 分类类型，爬虫按来源网站栏目设置，保存前通过 CategoryRule 映射为分类
 type CategoryTypes string
 
 const (
@@ -19,3 +21,62 @@ const (
 	// AnalysisCategory 分析文章
 	AnalysisCategory CategoryTypes = "analysis"
 )
+
+// ArticleCategory 文章所属分类，一篇文章可以属于多个分类
+type ArticleCategory struct {
+	ID        int           `gorm:"column:id;primaryKey" json:"id"`
+	ArticleID int           `gorm:"column:article_id;uniqueIndex:idx_article_category" json:"article_id"`
+	Category  CategoryTypes `gorm:"column:category;size:64;uniqueIndex:idx_article_category;index:idx_category" json:"category"`
+}
+
+func (c *ArticleCategory) TableName() string {
+	return "article_categories"
+}
+
+// Category 分类信息
+type Category struct {
+	ID         int           `gorm:"column:id;primaryKey" json:"id"`
+	Slug       CategoryTypes `gorm:"column:slug;size:64;uniqueIndex:idx_slug;not null" json:"slug"`
+	NameEN     string        `gorm:"column:name_en;size:64" json:"name_en"`
+	NameZH     string        `gorm:"column:name_zh;size:64" json:"name_zh"`
+	Sort       int           `gorm:"column:sort" json:"sort"`
+	Visible    bool          `gorm:"column:visible" json:"visible"`
+	CreateTime time.Time     `gorm:"column:create_time" json:"create_time"`
+	UpdateTime time.Time     `gorm:"column:update_time" json:"update_time"`
+}
+
+func (c *Category) TableName() string {
+	return "categories"
+}
+
+func (c *Category) GetNameByLang(lang string) string {
+	if IsChinese(lang) && c.NameZH != "" {
+		return ConvertChinese(c.NameZH, lang)
+	}
+
+	return c.NameEN
+}
+
+// AnyOrigin 适用于所有来源网站的映射规则
+const AnyOrigin = "*"
+
+// CategoryRule 来源网站栏目到分类的映射规则，Category 为空时丢弃该栏目文章
+type CategoryRule struct {
+	ID       int           `gorm:"column:id;primaryKey" json:"id"`
+	From     string        `gorm:"column:from;size:64;uniqueIndex:idx_from_section" json:"from"`
+	Section  CategoryTypes `gorm:"column:section;size:64;uniqueIndex:idx_from_section" json:"section"`
+	Category CategoryTypes `gorm:"column:category;size:64" json:"category"`
+}
+
+func (r *CategoryRule) TableName() string {
+	return "category_rules"
+}
+
+// defaultCategories 默认分类
+var defaultCategories = []Category{
+	{Slug: FeaturedCategory, NameEN: "Featured", NameZH: "精选", Sort: 1, Visible: true},
+	{Slug: LatestCategory, NameEN: "Latest", NameZH: "最新", Sort: 2, Visible: true},
+	{Slug: MostReadsCategory, NameEN: "Most Read", NameZH: "热门", Sort: 3, Visible: true},
+	{Slug: OpinionsCategory, NameEN: "Opinions", NameZH: "观点", Sort: 4, Visible: true},
+	{Slug: AnalysisCategory, NameEN: "Analysis", NameZH: "分析", Sort: 5, Visible: true},
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Coin 币种信息
+type Coin struct {
+	ID         int       `gorm:"column:id;primaryKey" json:"-"`
+	Slug       string    `gorm:"column:slug;size:64;uniqueIndex:idx_slug;not null" json:"slug"`
+	Symbol     string    `gorm:"column:symbol;size:32;index:idx_symbol" json:"symbol"`
+	Name       string    `gorm:"column:name;size:64" json:"name"`
+	Logo       string    `gorm:"column:logo;size:512" json:"logo"`
+	CreateTime time.Time `gorm:"column:create_time" json:"-"`
+	UpdateTime time.Time `gorm:"column:update_time" json:"-"`
+}
+
+func (c *Coin) TableName() string {
+	return "coins"
+}
+
+// Merge 补全缺失的币种信息
+func (c *Coin) Merge(other *Coin) {
+	if c.Symbol == "" {
+		c.Symbol = other.Symbol
+	}
+	if c.Name == "" {
+		c.Name = other.Name
+	}
+	if c.Logo == "" {
+		c.Logo = other.Logo
+	}
+}
+
+func (c *Coin) MarshalBinary() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c *Coin) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, c)
+}
+
+func (c *Coin) Bytes() []byte {
+	b, _ := c.MarshalBinary()
+	return b
+}
+
+// ArticleCoin 文章关联的币种，一篇文章可以关联多个币种
+type ArticleCoin struct {
+	ID        int `gorm:"column:id;primaryKey" json:"id"`
+	ArticleID int `gorm:"column:article_id;uniqueIndex:idx_article_coin" json:"article_id"`
+	CoinID    int `gorm:"column:coin_id;uniqueIndex:idx_article_coin;index:idx_coin" json:"coin_id"`
+}
+
+func (c *ArticleCoin) TableName() string {
+	return "article_coins"
+}
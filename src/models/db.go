@@ -42,7 +42,11 @@ func init() {
 	db = db.Debug()
 
 	// auto migrate
-	_ = db.AutoMigrate(&Article{})
+	dedupDuplicates(db)
+	_ = db.AutoMigrate(&Article{}, &ArticleCategory{}, &Category{}, &CategoryRule{}, &Coin{}, &ArticleCoin{}, &Story{}, &Duplicate{}, &ArticleEdit{}, &TranslationMemory{}, &ArticleTranslation{}, &Summary{}, &ImagePlaceholder{}, &TokenMigration{})
+	migrateTokens(db)
+	mergeCoinArticles(db)
+	seedCategories(db)
 
 	DB = db
 }
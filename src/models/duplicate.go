@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Duplicate 重复文章记录，Matched 为已收录的相似文章，同一对文章只记录一次
+type Duplicate struct {
+	ID         int       `gorm:"column:id;primaryKey" json:"id"`
+	Token      string    `gorm:"column:token;size:256;uniqueIndex:idx_token_matched" json:"token"`
+	From       string    `gorm:"column:from;size:64" json:"from"`
+	Title      string    `gorm:"column:title;size:256" json:"title"`
+	Link       string    `gorm:"column:link;size:512" json:"link"`
+	Matched    string    `gorm:"column:matched;size:256;uniqueIndex:idx_token_matched;index:idx_matched" json:"matched"`
+	Distance   int       `gorm:"column:distance" json:"distance"`
+	CreateTime time.Time `gorm:"column:create_time" json:"create_time"`
+	UpdateTime time.Time `gorm:"column:update_time" json:"update_time"`
+}
+
+func (d *Duplicate) TableName() string {
+	return "article_duplicates"
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// 编辑可以修改的文章字段，与 Article 的 json 字段名一致
+const (
+	FieldTitle      = "title"
+	FieldTitleCN    = "title_cn"
+	FieldAbstract   = "abstract"
+	FieldAbstractCN = "abstract_cn"
+)
+
+// EditableFields 编辑可以修改的文章字段
+var EditableFields = []string{FieldTitle, FieldTitleCN, FieldAbstract, FieldAbstractCN}
+
+const (
+	// EditActionEdit 修改字段并锁定
+	EditActionEdit = "edit"
+
+	// EditActionUnlock 解除锁定，之后由抓取任务更新
+	EditActionUnlock = "unlock"
+)
+
+// ArticleEdit 编辑修改文章的记录
+type ArticleEdit struct {
+	ID         int       `gorm:"column:id;primaryKey" json:"id"`
+	ArticleID  int       `gorm:"column:article_id;index:idx_article_id" json:"-"`
+	Token      string    `gorm:"column:token;size:256;index:idx_token" json:"token"`
+	Field      string    `gorm:"column:field;size:32" json:"field"`
+	Action     string    `gorm:"column:action;size:16" json:"action"`
+	Before     string    `gorm:"column:before;type:text" json:"before"`
+	After      string    `gorm:"column:after;type:text" json:"after"`
+	Editor     string    `gorm:"column:editor;size:64;index:idx_editor" json:"editor"`
+	CreateTime time.Time `gorm:"column:create_time" json:"create_time"`
+}
+
+func (e *ArticleEdit) TableName() string {
+	return "article_edits"
+}
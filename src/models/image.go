@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ImagePlaceholder 占位图，同一来源多篇文章共用的图片
+type ImagePlaceholder struct {
+	ID         int       `gorm:"column:id;primaryKey" json:"id"`
+	From       string    `gorm:"column:from;size:64;uniqueIndex:idx_from_hash" json:"from"`
+	Hash       string    `gorm:"column:hash;size:16;uniqueIndex:idx_from_hash" json:"hash"`
+	Count      int       `gorm:"column:count" json:"count"`
+	CreateTime time.Time `gorm:"column:create_time" json:"create_time"`
+	UpdateTime time.Time `gorm:"column:update_time" json:"update_time"`
+}
+
+func (p *ImagePlaceholder) TableName() string {
+	return "image_placeholders"
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"news/src/chinese"
+	"regexp"
+	"strings"
+)
+
+const (
+	// LangZHHant 繁体中文
+	LangZHHant = "zh-Hant"
+
+	// LangZHTW 台湾繁体中文
+	LangZHTW = "zh-TW"
+
+	// LangZHHK 香港繁体中文
+	LangZHHK = "zh-HK"
+)
+
+// langRegexp BCP-47 语言代码：语言、可选的文字和地区
+var langRegexp = regexp.MustCompile(`^([a-z]{2,3})(-[A-Z][a-z]{3})?(-([A-Z]{2}|[0-9]{3}))?$`)
+
+// NormalizeLang 规范化 BCP-47 语言代码，兼容旧的 ch 表示中文。
+// 英文和简体中文返回 en 和 zh，繁体中文返回 zh-Hant、zh-TW 或 zh-HK，其他语言保留语言和文字，无效代码返回 en
+func NormalizeLang(lang string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+	if parts[0] == "ch" || parts[0] == "cn" {
+		parts[0] = LangZH
+	}
+
+	match := langRegexp.FindStringSubmatch(strings.Join(parts, "-"))
+	if match == nil {
+		return LangEN
+	}
+	switch match[1] {
+	case LangEN:
+		return LangEN
+	case LangZH:
+		switch {
+		case match[4] == "TW":
+			return LangZHTW
+		case match[4] == "HK" || match[4] == "MO":
+			return LangZHHK
+		case match[2] == "-Hant":
+			return LangZHHant
+		}
+		return LangZH
+	}
+
+	return match[1] + match[2]
+}
+
+// IsChinese 是否为简体或繁体中文
+func IsChinese(lang string) bool {
+	switch NormalizeLang(lang) {
+	case LangZH, LangZHHant, LangZHTW, LangZHHK:
+		return true
+	}
+
+	return false
+}
+
+// ConvertChinese 将简体中文内容转换为语言代码对应的繁体中文，简体中文原样返回
+func ConvertChinese(text, lang string) string {
+	switch NormalizeLang(lang) {
+	case LangZHHant:
+		return chinese.Default().ToTraditional(text, "")
+	case LangZHTW:
+		return chinese.Default().ToTraditional(text, chinese.RegionTW)
+	case LangZHHK:
+		return chinese.Default().ToTraditional(text, chinese.RegionHK)
+	}
+
+	return text
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"news/src/logger"
+	"news/src/utils"
+	"strings"
+	"time"
+)
+
+// seedCategories 初始化默认分类和映射规则
+func seedCategories(db *gorm.DB) {
+	for _, category := range defaultCategories {
+		category.CreateTime = time.Now()
+		category.UpdateTime = time.Now()
+		if err := db.Where("slug = ?", category.Slug).FirstOrCreate(&category).Error; err != nil {
+			logger.Errorf("Failed to seed category %s: %s", category.Slug, err)
+			continue
+		}
+
+		rule := CategoryRule{From: AnyOrigin, Section: category.Slug, Category: category.Slug}
+		if err := db.Where("`from` = ? AND section = ?", rule.From, rule.Section).FirstOrCreate(&rule).Error; err != nil {
+			logger.Errorf("Failed to seed category rule %s: %s", category.Slug, err)
+		}
+	}
+}
+
+// pageCanonicalSources 旧版本按详情页声明的规范链接生成标识的来源网站，列表页收录的同一文章标识不同，需要按链接重新生成
+var pageCanonicalSources = []string{"beincrypto", "blockworks", "coindesk", "theblock", "thedefiant"}
+
+// migrateTokens 将旧文章标识迁移为按来源和链接规范化地址生成，最初的旧标识保存在 legacy_token 中，
+// 标识变更记录在 token_migrations 中由存储服务同步到 Redis 和 Elasticsearch。
+// 新标识已被其他文章使用时合并到该文章。没有链接的文章无法规范化，保留原标识不迁移
+func migrateTokens(db *gorm.DB) {
+	articles := make([]*Article, 0, 500)
+	migrated := 0
+	result := db.Model(&Article{}).
+		Select("id", "token", "legacy_token", "from", "title", "link", "canonical_url").
+		Where("canonical_url = '' OR canonical_url IS NULL OR `from` IN ?", pageCanonicalSources).
+		Where("link <> '' AND link IS NOT NULL").
+		FindInBatches(&articles, 500, func(tx *gorm.DB, batch int) error {
+			for _, article := range articles {
+				canonical := utils.CanonicalURL(article.Link)
+				if canonical == "" || canonical == article.CanonicalURL {
+					continue
+				}
+
+				old := article.Token
+				article.Token, article.CanonicalURL = "", canonical
+				token := article.GenToken()
+				values := map[string]interface{}{
+					"canonical_url": canonical,
+					"token":         token,
+				}
+				// 只记录最初的旧标识，避免重复迁移时被覆盖
+				if article.LegacyToken == "" {
+					legacy := old
+					if legacy == "" {
+						legacy = article.GenLegacyToken()
+					}
+					values["legacy_token"] = legacy
+				}
+
+				err := db.Transaction(func(tx *gorm.DB) error {
+					existing := &Article{}
+					tx.Model(existing).Select("id").Where("token = ? AND id <> ?", token, article.ID).First(existing)
+					if existing.ID > 0 {
+						if err := mergeArticle(tx, article.ID, existing.ID); err != nil {
+							return err
+						}
+					} else if err := tx.Model(&Article{}).Where("id = ?", article.ID).Updates(values).Error; err != nil {
+						return err
+					}
+
+					if old == "" || old == token {
+						return nil
+					}
+					return tx.Create(&TokenMigration{OldToken: old, NewToken: token, CreateTime: time.Now()}).Error
+				})
+				if err != nil {
+					return err
+				}
+				migrated++
+			}
+
+			return nil
+		})
+	if result.Error != nil {
+		logger.Errorf("Failed to migrate article tokens: %s", result.Error)
+		return
+	}
+	if migrated > 0 {
+		logger.Infof("Migrated %d article tokens", migrated)
+	}
+}
+
+// mergeArticle 将文章的币种和分类关联合并到另一篇文章后删除
+func mergeArticle(tx *gorm.DB, from, into int) error {
+	err := tx.Exec("INSERT IGNORE INTO article_coins (article_id, coin_id) SELECT ?, coin_id FROM article_coins WHERE article_id = ?", into, from).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Exec("INSERT IGNORE INTO article_categories (article_id, category) SELECT ?, category FROM article_categories WHERE article_id = ?", into, from).Error
+	if err != nil {
+		return err
+	}
+	if err = tx.Where("article_id = ?", from).Delete(&ArticleCoin{}).Error; err != nil {
+		return err
+	}
+	if err = tx.Where("article_id = ?", from).Delete(&ArticleCategory{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&Article{}, from).Error
+}
+
+// mergeCoinArticles 将旧版本以 xxx_coin 为来源收录的币种页面文章合并到同一来源的文章，
+// 已收录时合并到已收录的文章，否则改为原来源和对应的文章标识
+func mergeCoinArticles(db *gorm.DB) {
+	articles := make([]*Article, 0)
+	if err := db.Model(&Article{}).Select("id", "from", "title", "link", "canonical_url").Where("`from` LIKE ?", "%\\_coin").Find(&articles).Error; err != nil {
+		logger.Errorf("Failed to query coin articles: %s", err)
+		return
+	}
+
+	for _, article := range articles {
+		article.From = strings.TrimSuffix(article.From, "_coin")
+		article.Token = article.GenToken()
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			existing := &Article{}
+			tx.Model(existing).Select("id").Where("token = ? AND id <> ?", article.Token, article.ID).First(existing)
+			if existing.ID == 0 {
+				return tx.Model(&Article{}).Where("id = ?", article.ID).Updates(map[string]interface{}{
+					"from":  article.From,
+					"token": article.Token,
+				}).Error
+			}
+
+			return mergeArticle(tx, article.ID, existing.ID)
+		})
+		if err != nil {
+			logger.Errorf("Failed to merge coin article %d: %s", article.ID, err)
+		}
+	}
+	if len(articles) > 0 {
+		logger.Infof("Merged %d coin articles", len(articles))
+	}
+}
+
+// dedupDuplicates 创建唯一索引前清理同一对文章的重复记录，只保留最早的一条
+func dedupDuplicates(db *gorm.DB) {
+	m := db.Migrator()
+	if !m.HasTable(&Duplicate{}) || m.HasIndex(&Duplicate{}, "idx_token_matched") {
+		return
+	}
+
+	result := db.Exec("DELETE d1 FROM article_duplicates d1 JOIN article_duplicates d2 ON d1.token = d2.token AND d1.matched = d2.matched AND d1.id > d2.id")
+	if result.Error != nil {
+		logger.Errorf("Failed to clean duplicate records: %s", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		logger.Infof("Removed %d duplicate records", result.RowsAffected)
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Story 多个来源网站报道的同一事件，代表文章之外的文章作为“其他来源报道”展示
+type Story struct {
+	ID           int       `gorm:"column:id;primaryKey" json:"id"`
+	Token        string    `gorm:"column:token;size:256;uniqueIndex:idx_token" json:"token"`
+	ArticleToken string    `gorm:"column:article_token;size:256" json:"article_token"` // 代表文章
+	Title        string    `gorm:"column:title;size:256" json:"title"`
+	TitleCN      string    `gorm:"column:title_ch;size:256" json:"title_cn"`
+	Origins      []string  `gorm:"column:origins;serializer:json;type:text" json:"origins"`
+	Sources      int       `gorm:"column:sources;index:idx_sources" json:"sources"`
+	Articles     int       `gorm:"column:articles" json:"articles"`
+	FirstTime    time.Time `gorm:"column:first_time" json:"first_time"`
+	LastTime     time.Time `gorm:"column:last_time;index:idx_last_time" json:"last_time"`
+	CreateTime   time.Time `gorm:"column:create_time" json:"create_time"`
+	UpdateTime   time.Time `gorm:"column:update_time" json:"update_time"`
+}
+
+func (s *Story) TableName() string {
+	return "stories"
+}
+
+func (s *Story) GetTitleByLang(lang string) string {
+	if IsChinese(lang) && s.TitleCN != "" {
+		return ConvertChinese(s.TitleCN, lang)
+	}
+
+	return s.Title
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Summary 大模型生成的文章中英文简介，按文章和提示词、模型版本保存，避免每次抓取重新生成。
+// 生成失败时记录失败原因，重试时间前不再重新生成
+type Summary struct {
+	ID         int        `gorm:"column:id;primaryKey" json:"id"`
+	Token      string     `gorm:"column:token;size:256;uniqueIndex:idx_token_version" json:"token"`
+	Version    string     `gorm:"column:version;size:32;uniqueIndex:idx_token_version" json:"version"`
+	Abstract   string     `gorm:"column:abstract;type:text" json:"abstract"`
+	AbstractCN string     `gorm:"column:abstract_ch;type:text" json:"abstract_cn"`
+	Failure    string     `gorm:"column:failure;type:text" json:"failure"`
+	RetryTime  *time.Time `gorm:"column:retry_time" json:"retry_time"`
+	CreateTime time.Time  `gorm:"column:create_time" json:"create_time"`
+}
+
+func (s *Summary) TableName() string {
+	return "article_summaries"
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TokenMigration 文章标识变更记录，MySQL 迁移后由存储服务同步到 Redis 和 Elasticsearch
+type TokenMigration struct {
+	ID         int       `gorm:"column:id;primaryKey" json:"id"`
+	OldToken   string    `gorm:"column:old_token;size:256" json:"old_token"`
+	NewToken   string    `gorm:"column:new_token;size:256" json:"new_token"`
+	Synced     bool      `gorm:"column:synced;index:idx_synced" json:"synced"`
+	CreateTime time.Time `gorm:"column:create_time" json:"create_time"`
+}
+
+func (m *TokenMigration) TableName() string {
+	return "token_migrations"
+}
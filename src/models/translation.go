@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// TranslationMemory 翻译记忆，按原文哈希、目标语言和提示词版本保存译文
+type TranslationMemory struct {
+	ID         int       `gorm:"column:id;primaryKey" json:"id"`
+	Hash       string    `gorm:"column:hash;size:64;uniqueIndex:idx_hash_target_version" json:"hash"`
+	Target     string    `gorm:"column:target;size:16;uniqueIndex:idx_hash_target_version" json:"target"`
+	Version    string    `gorm:"column:version;size:32;uniqueIndex:idx_hash_target_version" json:"version"`
+	Source     string    `gorm:"column:source;type:text" json:"source"`
+	Text       string    `gorm:"column:text;type:text" json:"text"`
+	Translator string    `gorm:"column:translator;size:64" json:"translator"`
+	CreateTime time.Time `gorm:"column:create_time" json:"create_time"`
+	UpdateTime time.Time `gorm:"column:update_time" json:"update_time"`
+}
+
+func (m *TranslationMemory) TableName() string {
+	return "translation_memory"
+}
+
+// ArticleTranslation 文章英文和中文以外的译文，由英文标题和简介翻译
+type ArticleTranslation struct {
+	ID         int       `gorm:"column:id;primaryKey" json:"id"`
+	Token      string    `gorm:"column:token;size:256;uniqueIndex:idx_token_lang" json:"token"`
+	Lang       string    `gorm:"column:lang;size:16;uniqueIndex:idx_token_lang" json:"lang"`
+	Title      string    `gorm:"column:title;size:512" json:"title"`
+	Abstract   string    `gorm:"column:abstract;type:text" json:"abstract"`
+	Translator string    `gorm:"column:translator;size:64" json:"translator"`
+	CreateTime time.Time `gorm:"column:create_time" json:"create_time"`
+	UpdateTime time.Time `gorm:"column:update_time" json:"update_time"`
+}
+
+func (t *ArticleTranslation) TableName() string {
+	return "article_translations"
+}
@@ -1,12 +1,10 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"fmt"
 	"github.com/gocolly/colly"
 	"news/src/models"
 	"strings"
-	"time"
 )
 
 // BeinCryptoScrapy beincrypt news scraping using Colly
@@ -14,6 +12,7 @@ type BeinCryptoScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewBeinCryptoScrapy(q QueueWrapper) *BeinCryptoScrapy {
@@ -21,6 +20,7 @@ func NewBeinCryptoScrapy(q QueueWrapper) *BeinCryptoScrapy {
 		name:   "beincrypto",
 		domain: "https://www.beincrypto.com",
 		send:   q,
+		dates:  NewDateParser("UTC"),
 	}
 }
 
@@ -44,9 +44,7 @@ func (b *BeinCryptoScrapy) OnDetails(url string) (models.Article, bool) {
 		article.Image = image
 		article.Link = url
 		article.Abstract = description
-		if t, err := time.Parse(time.RFC3339, pubDate); err == nil {
-			article.PubDate = sql.NullTime{Time: t, Valid: true}
-		}
+		article.PubDate = b.dates.NullTime(pubDate)
 
 		success = true
 	})
@@ -67,17 +65,13 @@ func (b *BeinCryptoScrapy) OnList(path string, category models.CategoryTypes) mo
 		date := e.ChildAttr("time", "datetime")
 		image := strings.Split(strings.Split(images, ",")[1], " ")[1]
 
-		var pubDate time.Time
-		if t, err := time.Parse(time.RFC3339, date); err == nil {
-			pubDate = t
-		}
 		articles = append(articles, models.Article{
 			From:     b.name,
 			Category: category,
 			Title:    title,
 			Link:     link,
 			Image:    image,
-			PubDate:  sql.NullTime{Time: pubDate, Valid: true},
+			PubDate:  b.dates.NullTime(date),
 		})
 	})
 	s.Start()
@@ -1,13 +1,11 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"fmt"
 	"github.com/gocolly/colly"
 	"github.com/tidwall/gjson"
 	"news/src/logger"
 	"news/src/models"
-	"time"
 )
 
 // BinanceScrapy binance news scraping using Colly
@@ -15,6 +13,7 @@ type BinanceScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewBinanceScrapy(q QueueWrapper) *BinanceScrapy {
@@ -22,6 +21,7 @@ func NewBinanceScrapy(q QueueWrapper) *BinanceScrapy {
 		name:   "binance",
 		domain: "https://www.binance.com",
 		send:   q,
+		dates:  NewDateParser("UTC"),
 	}
 }
 
@@ -30,7 +30,6 @@ func (b *BinanceScrapy) OnListAPI(body []byte, category models.CategoryTypes) mo
 
 	data := gjson.GetBytes(body, "data.vos")
 	data.ForEach(func(_, i gjson.Result) bool {
-		t := time.Unix(i.Get("date").Int(), 0)
 		articles = append(articles, models.Article{
 			From:         b.name,
 			Category:     category,
@@ -39,7 +38,7 @@ func (b *BinanceScrapy) OnListAPI(body []byte, category models.CategoryTypes) mo
 			Abstract:     i.Get("subTitle").String(),
 			Link:         i.Get("webLink").String(),
 			Image:        i.Get("coverMeta.url").String(),
-			PubDate:      sql.NullTime{Time: t, Valid: true},
+			PubDate:      b.dates.NullUnix(i.Get("date").Int()),
 			Reads:        int(i.Get("viewCount").Int()),
 			Interactions: int(i.Get("likeCount").Int()),
 			Comments:     int(i.Get("commentCount").Int()),
@@ -1,18 +1,17 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"github.com/gocolly/colly"
 	"news/src/models"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type BitPieScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewBitPieScrapy(q QueueWrapper) *BitPieScrapy {
@@ -20,6 +19,7 @@ func NewBitPieScrapy(q QueueWrapper) *BitPieScrapy {
 		name:   "bitpie",
 		domain: "https://m.sc5b.net",
 		send:   q,
+		dates:  NewDateParser("Asia/Shanghai"),
 	}
 }
 
@@ -35,7 +35,6 @@ func (b *BitPieScrapy) Run() error {
 		image := e.ChildAttr("figure.block-image a img", "src")
 		date := e.ChildAttr("div.entry-meta-items time", "datetime")
 		readsText := e.ChildAttr("div.entry-meta-items span.meta-viewnums", "title")
-		pubDate, _ := time.Parse("2006-01-02 15:04:05", date)
 		reads, _ := strconv.Atoi(strings.Split(readsText, " ")[0])
 
 		b.send(models.Article{
@@ -46,7 +45,7 @@ func (b *BitPieScrapy) Run() error {
 			Author:   author,
 			Image:    image,
 			Abstract: description,
-			PubDate:  sql.NullTime{Time: pubDate, Valid: true},
+			PubDate:  b.dates.NullTime(date),
 			Reads:    reads,
 		})
 	})
@@ -59,8 +58,7 @@ func (b *BitPieScrapy) Run() error {
 	s.OnCallback("section#divPrevious ul.divPrevious div.side_new", func(e *colly.HTMLElement) {
 		title := e.ChildText("div.side-new-title a")
 		link := e.ChildAttr("div.side-new-title a", "href")
-		date := e.ChildText("div.side-new-time")[6:]
-		pubDate, _ := time.Parse("2006月01月02日", date)
+		date := e.ChildText("div.side-new-time")
 
 		b.send(models.Article{
 			From:     b.name,
@@ -68,7 +66,7 @@ func (b *BitPieScrapy) Run() error {
 			Title:    title,
 			Link:     link,
 			Author:   author,
-			PubDate:  sql.NullTime{Time: pubDate, Valid: true},
+			PubDate:  b.dates.NullTime(date),
 		})
 	})
 
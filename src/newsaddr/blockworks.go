@@ -1,13 +1,11 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"fmt"
 	"github.com/gocolly/colly"
 	"news/src/logger"
 	"news/src/models"
 	"strings"
-	"time"
 )
 
 // BlockWorksScrapy Blockworks news scraping using Colly
@@ -15,6 +13,7 @@ type BlockWorksScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewBlockWorksScrapy(q QueueWrapper) *BlockWorksScrapy {
@@ -22,6 +21,7 @@ func NewBlockWorksScrapy(q QueueWrapper) *BlockWorksScrapy {
 		name:   "blockworks",
 		domain: "https://blockworks.co",
 		send:   q,
+		dates:  NewDateParser("UTC"),
 	}
 }
 
@@ -38,12 +38,7 @@ func (b *BlockWorksScrapy) OnDetails(url string) models.Article {
 
 		article.Image = e.Request.AbsoluteURL(image)
 		article.Author = author[3 : len(author)-2]
-		if t, err := time.Parse(time.RFC3339, pubDate); err == nil {
-			article.PubDate = sql.NullTime{
-				Time:  t,
-				Valid: true,
-			}
-		}
+		article.PubDate = b.dates.NullTime(pubDate)
 	})
 	s.Start()
 
@@ -89,13 +84,7 @@ func (b *BlockWorksScrapy) OnHomepageNews() (models.ArticleList, models.ArticleL
 			article.Author = strings.Join(authors, " & ")
 			article.Link = c.Request.AbsoluteURL(link)
 			article.Image = c.Request.AbsoluteURL(image)
-
-			if t, err := time.Parse(time.RFC3339, pubDate); err == nil {
-				article.PubDate = sql.NullTime{
-					Time:  t,
-					Valid: true,
-				}
-			}
+			article.PubDate = b.dates.NullTime(pubDate)
 			featured = append(featured, article)
 		})
 
@@ -121,13 +110,7 @@ func (b *BlockWorksScrapy) OnHomepageNews() (models.ArticleList, models.ArticleL
 			} else {
 				article.Image = b.OnDetails(article.Link).Image
 			}
-
-			if t, err := time.Parse(time.RFC3339, pubDate); err == nil {
-				article.PubDate = sql.NullTime{
-					Time:  t,
-					Valid: true,
-				}
-			}
+			article.PubDate = b.dates.NullTime(pubDate)
 			featured = append(featured, article)
 		})
 	})
@@ -164,12 +147,7 @@ func (b *BlockWorksScrapy) OnOpinionNews() models.ArticleList {
 			Link:     link,
 			Image:    image,
 			Abstract: description,
-		}
-		if t, err := time.Parse(time.RFC3339, pubDate); err == nil {
-			article.PubDate = sql.NullTime{
-				Time:  t,
-				Valid: true,
-			}
+			PubDate:  b.dates.NullTime(pubDate),
 		}
 
 		articles = append(articles, article)
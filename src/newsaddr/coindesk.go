@@ -1,11 +1,9 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"github.com/gocolly/colly"
 	"news/src/models"
 	"strings"
-	"time"
 )
 
 // CoinDeskScrapy coindesk news scraping using Colly
@@ -13,6 +11,7 @@ type CoinDeskScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewCoinDeskScrapy(q QueueWrapper) *CoinDeskScrapy {
@@ -20,6 +19,7 @@ func NewCoinDeskScrapy(q QueueWrapper) *CoinDeskScrapy {
 		name:   "coindesk",
 		domain: "https://www.coindesk.com",
 		send:   q,
+		dates:  NewDateParser("America/New_York"),
 	}
 }
 
@@ -43,9 +43,7 @@ func (c *CoinDeskScrapy) OnDetails(url string) models.Article {
 
 		pubDate = strings.ReplaceAll(pubDate, "p.m.", "PM")
 		pubDate = strings.ReplaceAll(pubDate, "a.m.", "AM")
-		if t, err := time.Parse("Jan 2, 2006 at 3:04 PM MST", pubDate); err == nil {
-			article.PubDate = sql.NullTime{Time: t, Valid: true}
-		}
+		article.PubDate = c.dates.NullTime(pubDate)
 	})
 
 	s.Start()
@@ -0,0 +1,233 @@
+package newsaddr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"news/src/logger"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrZeroDate    = errors.New("zero date")
+	ErrFutureDate  = errors.New("future date")
+	ErrInvalidDate = errors.New("invalid date")
+
+	// defaultLayouts 通用日期格式，各来源可追加特有格式
+	defaultLayouts = []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02T15:04:05.000Z",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"2006/01/02 15:04:05",
+		"2006/01/02 15:04",
+		"2006/01/02",
+		"January 2, 2006, 3:04PM MST",
+		"January 2, 2006 3:04 PM MST",
+		"Jan 2, 2006 at 3:04 PM MST",
+		"January 02, 2006",
+		"January 2, 2006",
+		"Jan 2, 2006",
+		time.RFC1123Z,
+		time.RFC1123,
+	}
+
+	cnDateRegexp   = regexp.MustCompile(`(\d{4})\s*年\s*(\d{1,2})\s*月\s*(\d{1,2})\s*日`)
+	cnMDRegexp     = regexp.MustCompile(`^(\d{1,2})\s*月\s*(\d{1,2})\s*日`)
+	relativeRegexp = regexp.MustCompile(`^(\d+|an?|one)\s*([a-z\p{Han}]+?)\s*(ago|前|之前)$`)
+	unixRegexp     = regexp.MustCompile(`^\d{10}(\d{3})?$`)
+)
+
+// relativeUnits 相对时间单位
+var relativeUnits = map[string]time.Duration{
+	"second": time.Second, "seconds": time.Second, "sec": time.Second, "secs": time.Second, "秒": time.Second, "秒钟": time.Second,
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute, "分钟": time.Minute, "分": time.Minute,
+	"hour": time.Hour, "hours": time.Hour, "hr": time.Hour, "hrs": time.Hour, "小时": time.Hour, "个小时": time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour, "天": 24 * time.Hour, "日": 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour, "周": 7 * 24 * time.Hour, "星期": 7 * 24 * time.Hour, "个星期": 7 * 24 * time.Hour,
+}
+
+// calendarUnits 按日历计算的相对时间单位（月、年）
+var calendarUnits = map[string][2]int{
+	"month": {0, 1}, "months": {0, 1}, "月": {0, 1}, "个月": {0, 1},
+	"year": {1, 0}, "years": {1, 0}, "年": {1, 0},
+}
+
+// DateParser 日期时间解析，支持绝对时间、中英文相对时间和Unix时间戳，统一转换为UTC
+type DateParser struct {
+	loc     *time.Location
+	layouts []string
+	skew    time.Duration
+	now     func() time.Time
+}
+
+// NewDateParser 创建日期解析器，tz 为来源网站未声明时区时的默认时区
+func NewDateParser(tz string, layouts ...string) *DateParser {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		logger.Errorf("Unknown time zone %s, fallback to UTC: %s", tz, err)
+		loc = time.UTC
+	}
+
+	return &DateParser{
+		loc:     loc,
+		layouts: append(layouts, defaultLayouts...),
+		skew:    10 * time.Minute,
+		now:     time.Now,
+	}
+}
+
+// Parse 解析日期时间
+func (p *DateParser) Parse(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, ErrZeroDate
+	}
+
+	t, err := p.parse(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return p.validate(t)
+}
+
+// ParseUnix 解析Unix时间戳（秒或毫秒）
+func (p *DateParser) ParseUnix(ts int64) (time.Time, error) {
+	if ts <= 0 {
+		return time.Time{}, ErrZeroDate
+	}
+	if ts > 1e12 {
+		return p.validate(time.UnixMilli(ts))
+	}
+
+	return p.validate(time.Unix(ts, 0))
+}
+
+// NullTime 解析日期时间，失败时返回无效时间并记录日志
+func (p *DateParser) NullTime(value string) sql.NullTime {
+	t, err := p.Parse(value)
+	if err != nil {
+		logger.Warnf("Failed to parse date '%s': %s", value, err)
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+// NullUnix 解析Unix时间戳，失败时返回无效时间并记录日志
+func (p *DateParser) NullUnix(ts int64) sql.NullTime {
+	t, err := p.ParseUnix(ts)
+	if err != nil {
+		logger.Warnf("Failed to parse timestamp '%d': %s", ts, err)
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+func (p *DateParser) parse(value string) (time.Time, error) {
+	// Unix时间戳
+	if unixRegexp.MatchString(value) {
+		ts, _ := strconv.ParseInt(value, 10, 64)
+		if len(value) == 13 {
+			return time.UnixMilli(ts), nil
+		}
+		return time.Unix(ts, 0), nil
+	}
+
+	// 相对时间
+	if t, ok := p.parseRelative(value); ok {
+		return t, nil
+	}
+
+	// 中文日期转换为标准格式
+	value = p.normalize(value)
+	for _, layout := range p.layouts {
+		if t, err := time.ParseInLocation(layout, value, p.loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidDate, value)
+}
+
+// parseRelative 解析中英文相对时间，如 "3 hours ago"、"3小时前"、"刚刚"、"昨天 12:30"
+func (p *DateParser) parseRelative(value string) (time.Time, bool) {
+	now := p.now().In(p.loc)
+	v := strings.ToLower(strings.TrimSpace(value))
+
+	switch v {
+	case "just now", "now", "刚刚", "刚才":
+		return now, true
+	case "today", "今天":
+		return now, true
+	case "yesterday", "昨天":
+		return now.AddDate(0, 0, -1), true
+	}
+
+	for prefix, days := range map[string]int{"今天": 0, "昨天": -1, "前天": -2, "today": 0, "yesterday": -1} {
+		if rest, ok := strings.CutPrefix(v, prefix); ok {
+			if clock, err := time.ParseInLocation("15:04", strings.TrimSpace(rest), p.loc); err == nil {
+				day := now.AddDate(0, 0, days)
+				return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, p.loc), true
+			}
+		}
+	}
+
+	m := relativeRegexp.FindStringSubmatch(v)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	n := 1
+	if num, err := strconv.Atoi(m[1]); err == nil {
+		n = num
+	}
+	if d, ok := relativeUnits[m[2]]; ok {
+		return now.Add(-time.Duration(n) * d), true
+	}
+	if c, ok := calendarUnits[m[2]]; ok {
+		return now.AddDate(-n*c[0], -n*c[1], 0), true
+	}
+
+	return time.Time{}, false
+}
+
+// normalize 将中文日期转换为 2006-01-02 格式
+func (p *DateParser) normalize(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	if m := cnDateRegexp.FindStringSubmatchIndex(value); m != nil {
+		date := fmt.Sprintf("%04d-%02d-%02d", atoi(value[m[2]:m[3]]), atoi(value[m[4]:m[5]]), atoi(value[m[6]:m[7]]))
+		return strings.TrimSpace(date + " " + strings.TrimSpace(value[m[1]:]))
+	}
+	if m := cnMDRegexp.FindStringSubmatchIndex(value); m != nil { // 省略年份时取当前年份
+		date := fmt.Sprintf("%04d-%02d-%02d", p.now().In(p.loc).Year(), atoi(value[m[2]:m[3]]), atoi(value[m[4]:m[5]]))
+		return strings.TrimSpace(date + " " + strings.TrimSpace(value[m[1]:]))
+	}
+
+	return value
+}
+
+// validate 转换为UTC并拒绝零值和未来时间
+func (p *DateParser) validate(t time.Time) (time.Time, error) {
+	if t.IsZero() || t.Year() < 2000 {
+		return time.Time{}, ErrZeroDate
+	}
+	if t.After(p.now().Add(p.skew)) {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrFutureDate, t.Format(time.RFC3339))
+	}
+
+	return t.UTC(), nil
+}
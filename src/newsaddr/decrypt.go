@@ -1,14 +1,12 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"fmt"
 	"github.com/gocolly/colly"
 	"github.com/tidwall/gjson"
 	"news/src/logger"
 	"news/src/models"
 	"strings"
-	"time"
 )
 
 // DecryptScrapy decrypt news scraping using Colly
@@ -16,6 +14,7 @@ type DecryptScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewDecryptScrapy(q QueueWrapper) *DecryptScrapy {
@@ -23,6 +22,7 @@ func NewDecryptScrapy(q QueueWrapper) *DecryptScrapy {
 		name:   "decrypt",
 		domain: "https://decrypt.co",
 		send:   q,
+		dates:  NewDateParser("UTC"),
 	}
 }
 
@@ -46,9 +46,6 @@ func (d *DecryptScrapy) OnNewsAPI(body []byte, category models.CategoryTypes) mo
 	if data.IsArray() && len(data.Array()) > 0 {
 		data = data.Array()[0]
 		data.ForEach(func(_, i gjson.Result) bool {
-			date := i.Get("publishedAt").String()
-			pubDate, _ := time.Parse("2006-01-02T15:04:05", date)
-
 			articles = append(articles, models.Article{
 				From:     d.name,
 				Category: category,
@@ -57,7 +54,7 @@ func (d *DecryptScrapy) OnNewsAPI(body []byte, category models.CategoryTypes) mo
 				Image:    i.Get("featuredImage.src").String(),
 				Author:   i.Get("authors.data.0.name").String(),
 				Link:     fmt.Sprintf("%s%s", d.domain, i.Get("meta.hreflangs.0.path").String()),
-				PubDate:  sql.NullTime{Time: pubDate, Valid: true},
+				PubDate:  d.dates.NullTime(i.Get("publishedAt").String()),
 			})
 			return true
 		})
@@ -67,13 +64,26 @@ func (d *DecryptScrapy) OnNewsAPI(body []byte, category models.CategoryTypes) mo
 }
 
 func (d *DecryptScrapy) OnCoinAPI(buildId string, body []byte) {
-	slugs := gjson.GetBytes(body, "pageProps.priceQuotes.#.slug").Array()
-	if len(slugs) > 30 {
-		slugs = slugs[:30]
+	quotes := gjson.GetBytes(body, "pageProps.priceQuotes").Array()
+	if len(quotes) > 30 {
+		quotes = quotes[:30]
+	}
+	if len(quotes) == 0 {
+		return
+	}
+
+	coins := make([]models.Coin, 0, len(quotes))
+	for _, q := range quotes {
+		coins = append(coins, models.Coin{
+			Slug:   q.Get("slug").String(),
+			Symbol: strings.ToUpper(q.Get("symbol").String()),
+			Name:   q.Get("name").String(),
+			Logo:   q.Get("logo").String(),
+		})
 	}
 
 	index := 0
-	s := NewScrapy(fmt.Sprintf("%s/_next/data/%s/en-US/price/%s.json", d.domain, buildId, slugs[index]))
+	s := NewScrapy(fmt.Sprintf("%s/_next/data/%s/en-US/price/%s.json", d.domain, buildId, coins[index].Slug))
 	s.OnResponse(func(r *colly.Response) {
 		if r.StatusCode != 200 {
 			logger.Errorf("Response status code: %d", r.StatusCode)
@@ -83,26 +93,24 @@ func (d *DecryptScrapy) OnCoinAPI(buildId string, body []byte) {
 		if data.IsArray() && len(data.Array()) > 0 {
 			data = data.Array()[0]
 			data.ForEach(func(_, i gjson.Result) bool {
-				date := i.Get("publishedAt").String()
-				pubDate, _ := time.Parse("2006-01-02T15:04:05", date)
-
 				d.send(models.Article{
-					From:     d.name + "_coin",
-					Category: models.CategoryTypes(slugs[index].String()),
+					From:     d.name,
+					CoinPage: true,
+					Coins:    []models.Coin{coins[index]},
 					Title:    i.Get("title").String(),
 					Abstract: i.Get("blurb").String(),
 					Image:    i.Get("featuredImage.src").String(),
 					Author:   i.Get("authors.data.0.name").String(),
 					Link:     fmt.Sprintf("%s%s", d.domain, i.Get("meta.hreflangs.0.path").String()),
-					PubDate:  sql.NullTime{Time: pubDate, Valid: true},
+					PubDate:  d.dates.NullTime(i.Get("publishedAt").String()),
 				})
 				return true
 			})
 		}
 
 		index++
-		if index < len(slugs) {
-			err := r.Request.Visit(fmt.Sprintf("%s/_next/data/%s/en-US/price/%s.json", d.domain, buildId, slugs[index]))
+		if index < len(coins) {
+			err := r.Request.Visit(fmt.Sprintf("%s/_next/data/%s/en-US/price/%s.json", d.domain, buildId, coins[index].Slug))
 			if err != nil {
 				logger.Errorf("Next request failed: %v", err)
 			}
@@ -1,19 +1,18 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"fmt"
 	"github.com/gocolly/colly"
 	"github.com/tidwall/gjson"
 	"news/src/logger"
 	"news/src/models"
-	"time"
 )
 
 type JinSeScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewJinSeScrapy(q QueueWrapper) *JinSeScrapy {
@@ -21,6 +20,7 @@ func NewJinSeScrapy(q QueueWrapper) *JinSeScrapy {
 		name:   "jinse",
 		domain: "https://www.jinse.cn",
 		send:   q,
+		dates:  NewDateParser("Asia/Shanghai"),
 	}
 }
 
@@ -29,7 +29,6 @@ func (j *JinSeScrapy) OnFeatured(body []byte) models.ArticleList {
 
 	data := gjson.GetBytes(body, "data.list.#.object_1")
 	data.ForEach(func(_, i gjson.Result) bool {
-		ts := i.Get("published_at").Int()
 		articles = append(articles, models.Article{
 			From:     j.name,
 			Category: models.FeaturedCategory,
@@ -39,10 +38,7 @@ func (j *JinSeScrapy) OnFeatured(body []byte) models.ArticleList {
 			Image:    i.Get("cover").String(),
 			Reads:    int(i.Get("show_read_number").Int()),
 			Author:   i.Get("author.nickname").String(),
-			PubDate: sql.NullTime{
-				Time:  time.Unix(ts, 0),
-				Valid: true,
-			},
+			PubDate:  j.dates.NullUnix(i.Get("published_at").Int()),
 		})
 		return true
 	})
@@ -55,16 +51,12 @@ func (j *JinSeScrapy) OnNewsAPI(body []byte, category models.CategoryTypes) mode
 
 	data := gjson.GetBytes(body, "data")
 	data.ForEach(func(_, i gjson.Result) bool {
-		ts := i.Get("published_at").Int()
 		article := models.Article{
 			From:     j.name,
 			Category: category,
 			Title:    i.Get("title").String(),
 			Link:     i.Get("jump_url").String(),
-			PubDate: sql.NullTime{
-				Time:  time.Unix(ts, 0),
-				Valid: true,
-			},
+			PubDate:  j.dates.NullUnix(i.Get("published_at").Int()),
 		}
 		if category == models.MostReadsCategory {
 			article.Image = fmt.Sprintf("%s_small.png", i.Get("covers").String())
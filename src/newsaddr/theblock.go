@@ -1,12 +1,10 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"fmt"
 	"github.com/gocolly/colly"
 	"news/src/models"
 	"strings"
-	"time"
 )
 
 // TheBlockScrapy theblock news scraping using Colly
@@ -14,6 +12,7 @@ type TheBlockScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewTheBlockScrapy(q QueueWrapper) *TheBlockScrapy {
@@ -21,6 +20,7 @@ func NewTheBlockScrapy(q QueueWrapper) *TheBlockScrapy {
 		name:   "theblock",
 		domain: "https://www.theblock.co",
 		send:   q,
+		dates:  NewDateParser("America/New_York"),
 	}
 }
 
@@ -35,10 +35,10 @@ func (b *TheBlockScrapy) OnDetails(url string) models.Article {
 		pubDate := e.ChildText("div.ArticleTimestamps div.ArticleTimestamps__container")
 		description := e.ChildText("div.quickTake ul li:nth-of-type(1) span")
 
-		pubDate = strings.Split(pubDate, "•")[1][1:]
-		if t, err := time.Parse("January 2, 2006, 3:04PM MST", pubDate); err == nil {
-			article.PubDate = sql.NullTime{Time: t, Valid: true}
+		if parts := strings.Split(pubDate, "•"); len(parts) > 1 {
+			pubDate = parts[1]
 		}
+		article.PubDate = b.dates.NullTime(pubDate)
 
 		article.From = b.name
 		article.Link = url
@@ -73,19 +73,13 @@ func (b *TheBlockScrapy) Run() error {
 		image := e.ChildAttr("a > img[class$=image]", "src")
 		date := e.ChildText("div.meta__timestamp")
 
-		pubDate := sql.NullTime{}
-		if t, err := time.Parse("January 2, 2006, 3:04PM MST", date); err == nil {
-			pubDate.Time = t
-			pubDate.Valid = true
-		}
-
 		b.send(models.Article{
 			From:     b.name,
 			Category: models.FeaturedCategory,
 			Title:    title,
 			Link:     e.Request.AbsoluteURL(link),
 			Image:    image,
-			PubDate:  pubDate,
+			PubDate:  b.dates.NullTime(date),
 		})
 	})
 	s.Start()
@@ -97,19 +91,13 @@ func (b *TheBlockScrapy) Run() error {
 		image := e.ChildAttr("a > img[class$=image]", "src")
 		date := e.ChildText("div.meta__timestamp")
 
-		pubDate := sql.NullTime{}
-		if t, err := time.Parse("January 2, 2006, 3:04PM MST", date); err == nil {
-			pubDate.Time = t
-			pubDate.Valid = true
-		}
-
 		b.send(models.Article{
 			From:     b.name,
 			Category: models.FeaturedCategory,
 			Title:    title,
 			Link:     e.Request.AbsoluteURL(link),
 			Image:    image,
-			PubDate:  pubDate,
+			PubDate:  b.dates.NullTime(date),
 		})
 	})
 	s1.Start()
@@ -1,12 +1,10 @@
 package newsaddr
 
 import (
-	"database/sql"
 	"fmt"
 	"github.com/gocolly/colly"
 	"news/src/logger"
 	"news/src/models"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +14,7 @@ type TheDefiantScrapy struct {
 	name   string
 	domain string
 	send   QueueWrapper
+	dates  *DateParser
 }
 
 func NewTheDefiantScrapy(q QueueWrapper) *TheDefiantScrapy {
@@ -23,48 +22,12 @@ func NewTheDefiantScrapy(q QueueWrapper) *TheDefiantScrapy {
 		name:   "thedefiant",
 		domain: "https://thedefiant.io",
 		send:   q,
+		dates:  NewDateParser("UTC"),
 	}
 }
 
 func (t *TheDefiantScrapy) ParseRelativeTime(relativeTime string) (time.Time, error) {
-	if relativeTime == "" {
-		return time.Time{}, nil
-	}
-
-	if date, err := time.Parse("January 02, 2006", relativeTime); err == nil {
-		return date, nil
-	}
-
-	now := time.Now()
-	parts := strings.Split(relativeTime, " ")
-	if len(parts) != 3 {
-		return time.Time{}, fmt.Errorf("invalid relative time format")
-	}
-
-	value, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	unit := parts[1]
-	switch unit {
-	case "second", "seconds":
-		return now.Add(-time.Duration(value) * time.Second), nil
-	case "minute", "minutes":
-		return now.Add(-time.Duration(value) * time.Minute), nil
-	case "hour", "hours":
-		return now.Add(-time.Duration(value) * time.Hour), nil
-	case "day", "days":
-		return now.AddDate(0, 0, -value), nil
-	case "week", "weeks":
-		return now.AddDate(0, 0, -7*value), nil
-	case "month", "months":
-		return now.AddDate(0, -value, 0), nil
-	case "year", "years":
-		return now.AddDate(-value, 0, 0), nil
-	default:
-		return time.Time{}, fmt.Errorf("unknown time unit: %s", unit)
-	}
+	return t.dates.Parse(relativeTime)
 }
 
 func (t *TheDefiantScrapy) OnDetails(url string) (models.Article, bool) {
@@ -85,12 +48,7 @@ func (t *TheDefiantScrapy) OnDetails(url string) (models.Article, bool) {
 			date = strings.TrimSpace(parts[1])
 		}
 
-		if p, err := t.ParseRelativeTime(date); err == nil {
-			article.PubDate = sql.NullTime{
-				Time:  p,
-				Valid: true,
-			}
-		}
+		article.PubDate = t.dates.NullTime(date)
 
 		article.From = t.name
 		article.Title = title
@@ -117,11 +75,6 @@ func (t *TheDefiantScrapy) OnNewsList(url string, category models.CategoryTypes)
 		image := e.ChildAttr("div:nth-of-type(2) img.object-cover", "src")
 		date := strings.TrimSpace(e.DOM.Get(0).FirstChild.LastChild.Data)
 
-		var pubDate time.Time
-		if p, err := t.ParseRelativeTime(date); err == nil {
-			pubDate = p
-		}
-
 		articles = append(articles, models.Article{
 			From:     t.name,
 			Category: category,
@@ -129,7 +82,7 @@ func (t *TheDefiantScrapy) OnNewsList(url string, category models.CategoryTypes)
 			Link:     e.Request.AbsoluteURL(link),
 			Abstract: description,
 			Image:    e.Request.AbsoluteURL(image),
-			PubDate:  sql.NullTime{Time: pubDate, Valid: true},
+			PubDate:  t.dates.NullTime(date),
 		})
 	})
 	s.Start()
@@ -174,11 +127,6 @@ func (t *TheDefiantScrapy) Run() error {
 		image := e.ChildAttr("a img", "src")
 		date := strings.TrimSpace(e.DOM.Get(0).FirstChild.LastChild.FirstChild.Data)
 
-		var pubDate time.Time
-		if p, err := t.ParseRelativeTime(date); err == nil {
-			pubDate = p
-		}
-
 		link = e.Request.AbsoluteURL(link)
 		image = e.Request.AbsoluteURL(image)
 		t.send(models.Article{
@@ -187,7 +135,7 @@ func (t *TheDefiantScrapy) Run() error {
 			Title:    title,
 			Link:     link,
 			Image:    image,
-			PubDate:  sql.NullTime{Time: pubDate, Valid: true},
+			PubDate:  t.dates.NullTime(date),
 		})
 	})
 
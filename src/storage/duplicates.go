@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"news/src/config"
+	"news/src/logger"
+	"news/src/models"
+	"news/src/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DuplicateBandZSetKey 近似重复索引，按来源网站和原文语言区分，不区分数据版本，成员为 token|simhash，分数为收录时间
+const DuplicateBandZSetKey = "dedup:%s:%s:band:%d:%04x"
+
+// DuplicateIndex 基于 SimHash 分段索引的近似重复文章检测，同一来源网站时间窗口内原文标题相似的文章视为重复。
+// 索引按来源区分，不做跨来源匹配：不同来源的相似文章不丢弃，由 story.Clusterer 聚合为同一事件
+type DuplicateIndex struct {
+	client   *redis.Client
+	db       *gorm.DB
+	window   time.Duration
+	distance int
+}
+
+func NewDuplicateIndex() *DuplicateIndex {
+	r := config.Cfg.Redis
+	d := config.Cfg.Dedup
+	index := &DuplicateIndex{
+		client: redis.NewClient(&redis.Options{
+			Addr:     r.Addr,
+			Password: r.Password,
+			DB:       r.DB,
+		}),
+		db:       models.DB,
+		window:   time.Duration(d.Window) * time.Hour,
+		distance: d.Distance,
+	}
+	if index.window <= 0 {
+		index.window = 72 * time.Hour
+	}
+	if index.distance <= 0 || index.distance > 3 { // 分段索引只能保证距离不超过3的召回
+		index.distance = 3
+	}
+
+	return index
+}
+
+// Match 查找时间窗口内的相似文章，返回相似文章标识和汉明距离
+func (d *DuplicateIndex) Match(article *models.Article) (string, int, bool) {
+	ctx := context.Background()
+	hash := utils.SimHash(article.GetTitleByLang(article.Lang))
+	if hash == 0 {
+		return "", 0, false
+	}
+
+	since := strconv.FormatInt(time.Now().Add(-d.window).Unix(), 10)
+	for i, band := range utils.SimHashBands(hash) {
+		key := fmt.Sprintf(DuplicateBandZSetKey, article.From, article.Lang, i, band)
+		members, err := d.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: since, Max: "+inf"}).Result()
+		if err != nil {
+			logger.Errorf("Failed to query duplicate index %s: %s", key, err)
+			continue
+		}
+
+		for _, member := range members {
+			token, value, _ := strings.Cut(member, "|")
+			if token == article.Token { // 同一篇文章重复抓取
+				continue
+			}
+
+			other, err := strconv.ParseUint(value, 16, 64)
+			if err != nil {
+				continue
+			}
+			if distance := utils.HammingDistance(hash, other); distance <= d.distance {
+				return token, distance, true
+			}
+		}
+	}
+
+	return "", 0, false
+}
+
+// Add 将文章加入索引，并清理时间窗口外的记录
+func (d *DuplicateIndex) Add(article *models.Article) error {
+	ctx := context.Background()
+	hash := utils.SimHash(article.GetTitleByLang(article.Lang))
+	if hash == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	member := fmt.Sprintf("%s|%016x", article.Token, hash)
+	for i, band := range utils.SimHashBands(hash) {
+		key := fmt.Sprintf(DuplicateBandZSetKey, article.From, article.Lang, i, band)
+		pipe := d.client.Pipeline()
+		pipe.ZAdd(ctx, key, redis.Z{Member: member, Score: float64(now.Unix())})
+		pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Add(-d.window).Unix(), 10))
+		pipe.Expire(ctx, key, d.window)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Record 保存重复文章及其匹配的文章，重复抓取时只更新记录
+func (d *DuplicateIndex) Record(article *models.Article, matched string, distance int) error {
+	return d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}, {Name: "matched"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "link", "distance", "update_time"}),
+	}).Create(&models.Duplicate{
+		Token:      article.Token,
+		From:       article.From,
+		Title:      article.Title,
+		Link:       article.Link,
+		Matched:    matched,
+		Distance:   distance,
+		CreateTime: time.Now(),
+		UpdateTime: time.Now(),
+	}).Error
+}
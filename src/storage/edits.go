@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"news/src/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotEditable = errors.New("field is not editable")
+	ErrEmptyField  = errors.New("field is empty")
+)
+
+// keepLocked 保留编辑锁定的字段，不被重新抓取和翻译的结果覆盖
+func keepLocked(article, existingArticle *models.Article) {
+	article.Locked = existingArticle.Locked
+	for _, field := range existingArticle.Locked {
+		article.SetField(field, existingArticle.GetField(field))
+		article.RemoveReview(field)
+		if field == models.FieldAbstract || field == models.FieldAbstractCN {
+			article.AbstractGenerated = existingArticle.AbstractGenerated
+		}
+	}
+}
+
+// Edit 修改文章字段并锁定，unlock 中的字段解除锁定，同时保存修改记录。
+// 读取和修改在同一事务中并加行锁，抓取进程同时保存时等待编辑提交后再读取
+func (s *MySQLStorage) Edit(token, editor string, fields map[string]string, unlock []string) (*models.Article, error) {
+	article := &models.Article{}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token = ? OR legacy_token = ?", token, token).First(article).Error; err != nil {
+			return err
+		}
+
+		return applyEdits(tx, article, editor, fields, unlock)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	article.AddCategory(s.getCategories(article.ID)...)
+	article.AddCoin(s.getCoins(article.ID)...)
+	return article, nil
+}
+
+// applyEdits 在事务中修改已加锁的文章并保存修改记录
+func applyEdits(tx *gorm.DB, article *models.Article, editor string, fields map[string]string, unlock []string) error {
+	now := time.Now()
+	edits := make([]*models.ArticleEdit, 0)
+	for _, field := range models.EditableFields {
+		value, ok := fields[field]
+		if !ok {
+			continue
+		}
+		edits = append(edits, &models.ArticleEdit{
+			ArticleID:  article.ID,
+			Token:      article.Token,
+			Field:      field,
+			Action:     models.EditActionEdit,
+			Before:     article.GetField(field),
+			After:      value,
+			Editor:     editor,
+			CreateTime: now,
+		})
+		article.SetField(field, value)
+		article.Lock(field)
+		article.RemoveReview(field)
+		if field == models.FieldAbstract || field == models.FieldAbstractCN {
+			article.AbstractGenerated = false
+		}
+	}
+	for _, field := range unlock {
+		if !slices.Contains(article.Locked, field) {
+			continue
+		}
+		edits = append(edits, &models.ArticleEdit{
+			ArticleID:  article.ID,
+			Token:      article.Token,
+			Field:      field,
+			Action:     models.EditActionUnlock,
+			Before:     article.GetField(field),
+			After:      article.GetField(field),
+			Editor:     editor,
+			CreateTime: now,
+		})
+		article.Unlock(field)
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+
+	article.UpdateTime = now
+	if err := tx.Model(article).
+		Select("title", "title_ch", "abstract", "abstract_ch", "abstract_generated", "review", "locked", "update_time").
+		Updates(article).Error; err != nil {
+		return err
+	}
+
+	// 英文标题和简介修改后，其他语言重新翻译
+	if _, ok := fields[models.FieldTitle]; ok || fields[models.FieldAbstract] != "" {
+		if err := tx.Where("token = ?", article.Token).Delete(&models.ArticleTranslation{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Create(&edits).Error
+}
+
+// GetEdits 获取文章的修改记录，最近的在前
+func (s *MySQLStorage) GetEdits(token string) ([]*models.ArticleEdit, error) {
+	article, err := s.Get(token)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make([]*models.ArticleEdit, 0)
+	err = s.DB.Where("article_id = ?", article.ID).Order("id DESC").Find(&edits).Error
+
+	return edits, err
+}
+
+// mysql 获取 MySQL 存储，编辑修改以 MySQL 为准
+func (s *Service) mysql() (*MySQLStorage, error) {
+	for _, store := range s.storages {
+		if m, ok := store.(*MySQLStorage); ok {
+			return m, nil
+		}
+	}
+
+	return nil, errors.New("mysql storage not available")
+}
+
+// Edit 编辑修改文章字段，保存到 MySQL 后同步到 Redis 和 Elasticsearch
+func (s *Service) Edit(token, editor string, fields map[string]string, unlock []string) (*models.Article, error) {
+	for field, value := range fields {
+		if !slices.Contains(models.EditableFields, field) {
+			return nil, fmt.Errorf("%w: %s", ErrNotEditable, field)
+		}
+		if fields[field] = strings.TrimSpace(value); fields[field] == "" {
+			return nil, fmt.Errorf("%w: %s", ErrEmptyField, field)
+		}
+	}
+	for _, field := range unlock {
+		if !slices.Contains(models.EditableFields, field) {
+			return nil, fmt.Errorf("%w: %s", ErrNotEditable, field)
+		}
+	}
+
+	m, err := s.mysql()
+	if err != nil {
+		return nil, err
+	}
+	article, err := m.Edit(token, editor, fields, unlock)
+	if err != nil {
+		return nil, err
+	}
+
+	// Redis 只更新当前版本中已有的文章，不在本次抓取结果中的文章不重新加入列表
+	var errs []error
+	for _, store := range s.storages {
+		switch store := store.(type) {
+		case *MySQLStorage:
+		case *RedisStorage:
+			if err := store.Update(article); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			if err := store.Save(article); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return article, errors.Join(errs...)
+}
+
+// Update 更新当前版本中文章的编辑字段，文章不在当前版本时忽略
+func (s *RedisStorage) Update(article *models.Article) error {
+	ctx := context.Background()
+
+	key := s.sKey(NewsTokenKey, article.Token)
+	existingArticle := &models.Article{}
+	if err := s.client.Get(ctx, key).Scan(existingArticle); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}
+
+	for _, field := range models.EditableFields {
+		existingArticle.SetField(field, article.GetField(field))
+	}
+	existingArticle.AbstractGenerated = article.AbstractGenerated
+	existingArticle.Review = article.Review
+	existingArticle.Locked = article.Locked
+	existingArticle.UpdateTime = article.UpdateTime
+
+	return s.client.Set(ctx, key, existingArticle, 0).Err()
+}
+
+// GetEdits 获取文章的修改记录
+func (s *Service) GetEdits(token string) ([]*models.ArticleEdit, error) {
+	m, err := s.mysql()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.GetEdits(token)
+}
@@ -12,6 +12,7 @@ import (
 	"news/src/config"
 	"news/src/logger"
 	"news/src/models"
+	"news/src/utils"
 	"os"
 	"sort"
 	"strings"
@@ -28,17 +29,58 @@ const (
             "token": {
                 "type": "keyword"
             },
+            "canonical_url": {
+                "type": "keyword"
+            },
+            "lang": {
+                "type": "keyword"
+            },
             "title": {
                 "type": "text",
                 "analyzer": "autocomplete",
                 "search_analyzer": "standard"
             },
             "title_ch": {
-                "type": "text"
+                "type": "text",
+                "analyzer": "cjk"
             },
             "category": {
                 "type": "keyword"
             },
+            "categories": {
+                "type": "keyword"
+            },
+            "tags": {
+                "type": "keyword"
+            },
+            "topics": {
+                "type": "keyword"
+            },
+            "story": {
+                "type": "keyword"
+            },
+            "review": {
+                "type": "keyword"
+            },
+            "locked": {
+                "type": "keyword"
+            },
+            "coins": {
+                "properties": {
+                    "slug": {
+                        "type": "keyword"
+                    },
+                    "symbol": {
+                        "type": "keyword"
+                    },
+                    "name": {
+                        "type": "text"
+                    },
+                    "logo": {
+                        "type": "text"
+                    }
+                }
+            },
             "from": {
                 "type": "keyword"
             },
@@ -46,11 +88,28 @@ const (
                 "type": "keyword"
             },
             "abstract": {
-                "type": "text"
+                "type": "text",
+                "analyzer": "english"
+            },
+            "abstract_ch": {
+                "type": "text",
+                "analyzer": "cjk"
+            },
+            "abstract_generated": {
+                "type": "boolean"
             },
             "image": {
                 "type": "text"
             },
+            "image_source": {
+                "type": "text"
+            },
+            "thumbnail": {
+                "type": "text"
+            },
+            "image_hash": {
+                "type": "keyword"
+            },
             "link": {
                 "type": "text"
             },
@@ -171,6 +230,13 @@ func (s *ElasticsearchStorage) Get(token string) (*models.Article, error) {
 
 func (s *ElasticsearchStorage) Save(article *models.Article) error {
 	article.Token = article.GenToken()
+	article.AddCategory(article.Category)
+
+	return s.put(article)
+}
+
+// put 创建文章索引，已存在时更新
+func (s *ElasticsearchStorage) put(article *models.Article) error {
 	resp, err := s.client.Create(s.index, article.Token, bytes.NewReader(article.Bytes()))
 	if err != nil {
 		logger.Errorf("Error saving article to Elasticsearch: %v", err)
@@ -193,10 +259,12 @@ func (s *ElasticsearchStorage) Save(article *models.Article) error {
 }
 
 func (s *ElasticsearchStorage) SaveCoin(article *models.Article) error {
-	return nil
+	article.Token = article.GenToken()
+
+	return s.put(article)
 }
 
-func (s *ElasticsearchStorage) GetHomeList(category string, page, size int) ([]*models.Article, int64, error) {
+func (s *ElasticsearchStorage) GetHomeList(category, topic string, page, size int) ([]*models.Article, int64, error) {
 	return nil, 0, errors.New("not implemented")
 }
 
@@ -216,7 +284,15 @@ func (s *ElasticsearchStorage) GetOriginsByCategory(category string) ([]string,
 	return nil, errors.New("not implemented")
 }
 
-func (s *ElasticsearchStorage) NewsSearch(keyword string, page, size int) ([]*models.Article, int64, error) {
+func (s *ElasticsearchStorage) GetCoinList(page, size int) ([]*models.Coin, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (s *ElasticsearchStorage) GetListByCoin(slug string, page, size int) ([]*models.Article, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (s *ElasticsearchStorage) NewsSearch(keyword, topic string, page, size int) ([]*models.Article, int64, error) {
 	var (
 		articles []*models.Article
 		count    int64
@@ -224,7 +300,7 @@ func (s *ElasticsearchStorage) NewsSearch(keyword string, page, size int) ([]*mo
 
 	resp, err := s.client.Search(
 		s.client.Search.WithIndex(s.index),
-		s.client.Search.WithQuery(keyword),
+		s.client.Search.WithBody(strings.NewReader(searchQuery(keyword, topic))),
 		s.client.Search.WithFrom((page-1)*size),
 		s.client.Search.WithSize(size),
 		s.client.Search.WithSort("pub_date.Time:desc", "reads:desc"),
@@ -257,6 +333,33 @@ func (s *ElasticsearchStorage) NewsSearch(keyword string, page, size int) ([]*mo
 	return articles, count, nil
 }
 
+// searchQuery 搜索条件，按关键词语言搜索对应语言分词的标题和简介，无法判断语言时搜索全部
+func searchQuery(keyword, topic string) string {
+	query := map[string]interface{}{}
+	if keyword != "" {
+		fields := []string{"title^2", "abstract", "title_ch^2", "abstract_ch"}
+		switch utils.DetectLang(keyword) {
+		case models.LangEN:
+			fields = []string{"title^2", "abstract"}
+		case models.LangZH:
+			fields = []string{"title_ch^2", "abstract_ch"}
+		}
+		query["must"] = map[string]interface{}{
+			"query_string": map[string]interface{}{"query": keyword, "fields": fields},
+		}
+	}
+	if topic != "" {
+		query["filter"] = map[string]interface{}{
+			"term": map[string]interface{}{"topics": topic},
+		}
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{"bool": query},
+	})
+	return string(body)
+}
+
 func (s *ElasticsearchStorage) Restore() error {
 	mixIndex := fmt.Sprintf("%s.*", config.Cfg.Elastic.Index)
 	resp, err := s.client.Cat.Indices(s.client.Cat.Indices.WithIndex(mixIndex), s.client.Cat.Indices.WithFormat("JSON"))
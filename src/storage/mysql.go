@@ -3,12 +3,17 @@ package storage
 import (
 	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"news/src/models"
+	"slices"
+	"sync"
 	"time"
 )
 
 type MySQLStorage struct {
 	DB *gorm.DB
+
+	locks sync.Map
 }
 
 func NewMySQLStorage(version int64) *MySQLStorage {
@@ -26,42 +31,203 @@ func (s *MySQLStorage) SetVersion(version int64) {
 
 func (s *MySQLStorage) Get(token string) (*models.Article, error) {
 	article := &models.Article{}
-	err := s.DB.Model(article).Where("token = ?", token).First(article).Error
+	err := s.DB.Model(article).Where("token = ? OR legacy_token = ?", token, token).First(article).Error
+	if err != nil {
+		return article, err
+	}
 
-	return article, err
+	article.AddCategory(s.getCategories(article.ID)...)
+	article.AddCoin(s.getCoins(article.ID)...)
+	return article, nil
 }
 
-func (s *MySQLStorage) Save(article *models.Article) error {
+// getCategories 获取文章所属的所有分类
+func (s *MySQLStorage) getCategories(id int) []models.CategoryTypes {
+	categories := make([]models.CategoryTypes, 0)
+	s.DB.Model(&models.ArticleCategory{}).Where("article_id = ?", id).Order("id").Pluck("category", &categories)
+
+	return categories
+}
+
+// saveCategories 保存文章所属分类，并合并已保存的分类
+func (s *MySQLStorage) saveCategories(article *models.Article) error {
+	rows := make([]models.ArticleCategory, 0, len(article.Categories))
+	for _, category := range article.Categories {
+		rows = append(rows, models.ArticleCategory{
+			ArticleID: article.ID,
+			Category:  category,
+		})
+	}
+	if len(rows) > 0 {
+		if err := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+
+	article.AddCategory(s.getCategories(article.ID)...)
+	return nil
+}
+
+// getCoins 获取文章关联的所有币种
+func (s *MySQLStorage) getCoins(id int) []models.Coin {
+	coins := make([]models.Coin, 0)
+	s.DB.Model(&models.Coin{}).
+		Joins("JOIN article_coins ON article_coins.coin_id = coins.id").
+		Where("article_coins.article_id = ?", id).
+		Order("article_coins.id").
+		Find(&coins)
+
+	return coins
+}
+
+// saveCoins 保存币种信息和文章关联的币种，并合并已关联的币种
+func (s *MySQLStorage) saveCoins(article *models.Article) error {
+	rows := make([]models.ArticleCoin, 0, len(article.Coins))
+	for _, coin := range article.Coins {
+		coin.CreateTime = time.Now()
+		coin.UpdateTime = time.Now()
+		// 只更新非空的币种信息
+		columns := []string{"update_time"}
+		for column, value := range map[string]string{"symbol": coin.Symbol, "name": coin.Name, "logo": coin.Logo} {
+			if value != "" {
+				columns = append(columns, column)
+			}
+		}
+		err := s.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "slug"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).Create(&coin).Error
+		if err != nil {
+			return err
+		}
+
+		// 冲突更新时 MySQL 不返回已有记录的主键
+		if err = s.DB.Model(&models.Coin{}).Where("slug = ?", coin.Slug).Pluck("id", &coin.ID).Error; err != nil {
+			return err
+		}
+		rows = append(rows, models.ArticleCoin{
+			ArticleID: article.ID,
+			CoinID:    coin.ID,
+		})
+	}
+	if len(rows) > 0 {
+		if err := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+
+	article.AddCoin(s.getCoins(article.ID)...)
+	return nil
+}
+
+// lock 同一篇文章可能从多个分类同时保存，按文章标识加锁
+func (s *MySQLStorage) lock(token string) func() {
+	l, _ := s.locks.LoadOrStore(token, &sync.Mutex{})
+	mu := l.(*sync.Mutex)
+	mu.Lock()
+
+	return func() {
+		mu.Unlock()
+	}
+}
+
+// keepTranslations 本次翻译失败时保留已保存的译文，中文原文的英文翻译失败时字段仍为中文，同样保留已保存的英文
+func keepTranslations(article, existingArticle *models.Article) {
+	if article.TitleCN == "" {
+		article.TitleCN = existingArticle.TitleCN
+	}
+	if article.AbstractCN == "" {
+		article.AbstractCN = existingArticle.AbstractCN
+	}
+
+	for _, field := range article.Untranslated {
+		value := existingArticle.GetField(field)
+		if value == "" || slices.Contains(existingArticle.Review, field) {
+			continue
+		}
+		article.SetField(field, value)
+		article.RemoveReview(field)
+	}
+}
+
+// findExisting 根据文章标识查找已保存的文章并加行锁，兼容迁移前按标题生成的标识。
+// 须在事务中调用，避免读取后被 API 进程的编辑修改覆盖
+func findExisting(tx *gorm.DB, article *models.Article) *models.Article {
+	tx = tx.Clauses(clause.Locking{Strength: "UPDATE"})
 	existingArticle := &models.Article{}
+	tx.Model(existingArticle).Where("token = ?", article.Token).First(existingArticle)
+	if existingArticle.ID == 0 {
+		tx.Model(existingArticle).Where("legacy_token = ? AND `from` = ?", article.GenLegacyToken(), article.From).First(existingArticle)
+	}
+
+	return existingArticle
+}
+
+func (s *MySQLStorage) Save(article *models.Article) error {
 	article.Token = article.GenToken()
-	s.DB.Model(existingArticle).Where("title = ?", article.Title).First(existingArticle)
-	if existingArticle.ID > 0 {
-		article.ID = existingArticle.ID
-		article.CreateTime = existingArticle.CreateTime
-	} else {
-		article.CreateTime = time.Now()
+	article.AddCategory(article.Category)
+
+	unlock := s.lock(article.Token)
+	defer unlock()
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		existingArticle := findExisting(tx, article)
+		if existingArticle.ID > 0 {
+			article.ID = existingArticle.ID
+			article.LegacyToken = existingArticle.LegacyToken
+			if existingArticle.Category != "" { // 保留首次收录的分类作为主分类，币种页面收录的文章没有分类
+				article.Category = existingArticle.Category
+			}
+			article.CreateTime = existingArticle.CreateTime
+			keepTranslations(article, existingArticle)
+			keepLocked(article, existingArticle)
+		} else {
+			article.CreateTime = time.Now()
+		}
+
+		article.UpdateTime = time.Now()
+		return tx.Save(article).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.saveCoins(article); err != nil {
+		return err
 	}
 
-	article.UpdateTime = time.Now()
-	return s.DB.Save(article).Error
+	return s.saveCategories(article)
 }
 
+// SaveCoin 保存币种页面的文章，文章已收录时只关联币种，后续存储使用已收录的文章信息
 func (s *MySQLStorage) SaveCoin(article *models.Article) error {
-	existingArticle := &models.Article{}
 	article.Token = article.GenToken()
-	s.DB.Model(existingArticle).Where("title = ?", article.Title).First(existingArticle)
-	if existingArticle.ID > 0 {
-		article.ID = existingArticle.ID
-		article.CreateTime = existingArticle.CreateTime
-	} else {
+
+	unlock := s.lock(article.Token)
+	defer unlock()
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		existingArticle := findExisting(tx, article)
+		if existingArticle.ID > 0 {
+			coins := article.Coins
+			*article = *existingArticle
+			article.Coins = coins
+			return nil
+		}
+
 		article.CreateTime = time.Now()
+		article.UpdateTime = time.Now()
+		return tx.Save(article).Error
+	})
+	if err != nil {
+		return err
 	}
+	article.AddCategory(s.getCategories(article.ID)...)
 
-	article.UpdateTime = time.Now()
-	return s.DB.Save(article).Error
+	return s.saveCoins(article)
 }
 
-func (s *MySQLStorage) GetHomeList(category string, page, size int) ([]*models.Article, int64, error) {
+func (s *MySQLStorage) GetHomeList(category, topic string, page, size int) ([]*models.Article, int64, error) {
 	return nil, 0, errors.New("not implemented")
 }
 
@@ -81,7 +247,15 @@ func (s *MySQLStorage) GetOriginsByCategory(category string) ([]string, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (s *MySQLStorage) NewsSearch(keyword string, page, size int) ([]*models.Article, int64, error) {
+func (s *MySQLStorage) GetCoinList(page, size int) ([]*models.Coin, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (s *MySQLStorage) GetListByCoin(slug string, page, size int) ([]*models.Article, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (s *MySQLStorage) NewsSearch(keyword, topic string, page, size int) ([]*models.Article, int64, error) {
 	return nil, 0, errors.New("not implemented")
 }
 
@@ -1,23 +1,49 @@
 package storage
 
 import (
+	"crypto/subtle"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"news/src/config"
+	"news/src/logger"
 	"news/src/models"
+	"news/src/topic"
+	"news/src/translator"
 	"news/src/utils"
+	"slices"
+	"time"
 )
 
 // NewsService 资讯服务
 type NewsService struct {
-	store *Service
+	store        *Service
+	taxonomy     *Taxonomy
+	stories      *Stories
+	translations *Translations
 }
 
 // NewNewsService creates a new NewsService
 func NewNewsService() *NewsService {
 	return &NewsService{
-		store: NewService(),
+		store:        NewService(),
+		taxonomy:     NewTaxonomy(),
+		stories:      NewStories(),
+		translations: NewTranslations(translator.New()),
 	}
 }
 
+// localize 加载文章指定语言的已有译文，没有译文时使用英文，允许按需翻译的语言在后台翻译后供之后的请求使用
+func (s *NewsService) localize(lang string, articles ...*models.Article) {
+	missing, err := s.translations.Load(lang, articles...)
+	if err != nil {
+		logger.Warnf("Failed to load %s translations: %s", lang, err)
+		return
+	}
+
+	s.translations.Queue(lang, missing...)
+}
+
 func (s *NewsService) Release() {
 	s.store.Release()
 }
@@ -26,6 +52,7 @@ func (s *NewsService) Release() {
 func (s *NewsService) HomeHandler(c *utils.ApiContext) {
 	req := struct {
 		Category string `form:"category"`
+		Topic    string `form:"topic"`
 		Page     int    `form:"page,default=1" binding:"required"`
 		PageSize int    `form:"page_size,default=15" binding:"required"`
 		Lang     string `form:"lang,default=en"`
@@ -35,14 +62,9 @@ func (s *NewsService) HomeHandler(c *utils.ApiContext) {
 		return
 	}
 
-	list, total := s.store.GetHomeList(req.Category, req.Page, req.PageSize)
+	list, total := s.store.GetHomeList(req.Category, req.Topic, req.Page, req.PageSize)
 
-	articles := make([]articleInfo, 0, len(list))
-	for _, article := range list {
-		articles = append(articles, newArticleInfo(article, req.Lang))
-	}
-
-	c.Pager(int(total), req.Page, req.PageSize, articles)
+	c.Pager(int(total), req.Page, req.PageSize, s.collapseStories(list, req.Lang))
 }
 
 // HomeListHandler 主页完整列表
@@ -55,6 +77,10 @@ func (s *NewsService) HomeListHandler(c *utils.ApiContext) {
 		c.Error(404, "资源未找到")
 		return
 	}
+	// 完整列表只使用已有的译文
+	if _, err = s.translations.Load(lang, list...); err != nil {
+		logger.Warnf("Failed to load %s translations: %s", lang, err)
+	}
 
 	articles := make([]articleInfo, 0, len(list))
 	for _, article := range list {
@@ -78,6 +104,95 @@ func (s *NewsService) HomeLinkHandler(c *utils.ApiContext) {
 	})
 }
 
+// editorName 根据请求头中的编辑密钥获取编辑名称，未配置密钥的编辑不能修改
+func editorName(c *utils.ApiContext) (string, bool) {
+	key := c.GetHeader("X-Editor-Key")
+	if key == "" {
+		return "", false
+	}
+	for name, editor := range config.Cfg.Editor {
+		if editor.Key != "" && subtle.ConstantTimeCompare([]byte(editor.Key), []byte(key)) == 1 {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// ArticleEditHandler 编辑修改文章标题和简介，修改的字段不再被抓取任务覆盖
+func (s *NewsService) ArticleEditHandler(c *utils.ApiContext) {
+	editor, ok := editorName(c)
+	if !ok {
+		c.Error(403, "没有编辑权限")
+		return
+	}
+
+	req := struct {
+		Title      *string  `form:"title" json:"title"`
+		TitleCN    *string  `form:"title_cn" json:"title_cn"`
+		Abstract   *string  `form:"abstract" json:"abstract"`
+		AbstractCN *string  `form:"abstract_cn" json:"abstract_cn"`
+		Unlock     []string `form:"unlock" json:"unlock"`
+	}{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(400, "参数错误")
+		return
+	}
+
+	fields := make(map[string]string)
+	for field, value := range map[string]*string{
+		models.FieldTitle:      req.Title,
+		models.FieldTitleCN:    req.TitleCN,
+		models.FieldAbstract:   req.Abstract,
+		models.FieldAbstractCN: req.AbstractCN,
+	} {
+		if value != nil {
+			fields[field] = *value
+		}
+	}
+
+	article, err := s.store.Edit(c.Param("token"), editor, fields, req.Unlock)
+	if errors.Is(err, ErrNotEditable) || errors.Is(err, ErrEmptyField) {
+		c.Error(400, "参数错误")
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Error(404, "资源未找到")
+		return
+	}
+	if err != nil {
+		logger.Errorf("Failed to edit article %s: %s", c.Param("token"), err)
+		c.Error(500, "修改文章失败")
+		return
+	}
+
+	c.Ok(gin.H{
+		"token":       article.Token,
+		"title":       article.Title,
+		"title_cn":    article.TitleCN,
+		"abstract":    article.Abstract,
+		"abstract_cn": article.AbstractCN,
+		"locked":      article.Locked,
+		"review":      article.Review,
+	})
+}
+
+// ArticleEditListHandler 文章修改记录
+func (s *NewsService) ArticleEditListHandler(c *utils.ApiContext) {
+	if _, ok := editorName(c); !ok {
+		c.Error(403, "没有编辑权限")
+		return
+	}
+
+	edits, err := s.store.GetEdits(c.Param("token"))
+	if err != nil {
+		c.Error(404, "资源未找到")
+		return
+	}
+
+	c.Ok(edits)
+}
+
 // HomeOriginListHandler 分类来源列表
 func (s *NewsService) HomeOriginListHandler(c *utils.ApiContext) {
 	category := c.PostForm("category")
@@ -118,6 +233,7 @@ func (s *NewsService) NewsReadListHandler(c *utils.ApiContext) {
 		if _, ok := originMap[key]; !ok {
 			continue
 		}
+		s.localize(req.Lang, items...)
 
 		articles[key] = make([]articleInfo, 0, len(items))
 		for _, article := range items {
@@ -146,6 +262,7 @@ func (s *NewsService) NewsOriginListHandler(c *utils.ApiContext) {
 		c.Error(500, "获取文章列表失败")
 		return
 	}
+	s.localize(params.Lang, list...)
 
 	articles := make([]articleInfo, 0, len(list))
 	for _, article := range list {
@@ -159,6 +276,7 @@ func (s *NewsService) NewsOriginListHandler(c *utils.ApiContext) {
 func (s *NewsService) NewsSearchHandler(c *utils.ApiContext) {
 	req := struct {
 		Keyword  string `form:"keyword"`
+		Topic    string `form:"topic"`
 		Lang     string `form:"lang"`
 		Page     int    `form:"page,default=1" binding:"gt=0"`
 		PageSize int    `form:"page_size,default=15" binding:"gt=0"`
@@ -168,7 +286,56 @@ func (s *NewsService) NewsSearchHandler(c *utils.ApiContext) {
 		return
 	}
 
-	list, count := s.store.NewsSearch(req.Keyword, req.Page, req.PageSize)
+	list, count := s.store.NewsSearch(req.Keyword, req.Topic, req.Page, req.PageSize)
+	s.localize(req.Lang, list...)
+	articles := make([]articleInfo, 0, len(list))
+	for _, article := range list {
+		articles = append(articles, newArticleInfo(article, req.Lang))
+	}
+
+	c.Pager(int(count), req.Page, req.PageSize, articles)
+}
+
+// CoinListHandler 币种列表
+func (s *NewsService) CoinListHandler(c *utils.ApiContext) {
+	req := struct {
+		Page     int `form:"page,default=1" binding:"gt=0"`
+		PageSize int `form:"page_size,default=30" binding:"gt=0"`
+	}{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(400, "参数错误")
+		return
+	}
+
+	coins, count, err := s.store.GetCoinList(req.Page, req.PageSize)
+	if err != nil {
+		c.Error(500, "获取币种列表失败")
+		return
+	}
+
+	c.Pager(int(count), req.Page, req.PageSize, coins)
+}
+
+// CoinNewsListHandler 币种文章列表
+func (s *NewsService) CoinNewsListHandler(c *utils.ApiContext) {
+	slug := c.Param("slug")
+	req := struct {
+		Page     int    `form:"page,default=1" binding:"gt=0"`
+		PageSize int    `form:"page_size,default=15" binding:"gt=0"`
+		Lang     string `form:"lang,default=en"`
+	}{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(400, "参数错误")
+		return
+	}
+
+	list, count, err := s.store.GetListByCoin(slug, req.Page, req.PageSize)
+	if err != nil {
+		c.Error(500, "获取文章列表失败")
+		return
+	}
+	s.localize(req.Lang, list...)
+
 	articles := make([]articleInfo, 0, len(list))
 	for _, article := range list {
 		articles = append(articles, newArticleInfo(article, req.Lang))
@@ -177,27 +344,241 @@ func (s *NewsService) NewsSearchHandler(c *utils.ApiContext) {
 	c.Pager(int(count), req.Page, req.PageSize, articles)
 }
 
+// StoryListHandler 多来源事件列表，按报道来源数量排序
+func (s *NewsService) StoryListHandler(c *utils.ApiContext) {
+	req := struct {
+		Hours    int    `form:"hours,default=72" binding:"gt=0"`
+		Page     int    `form:"page,default=1" binding:"gt=0"`
+		PageSize int    `form:"page_size,default=15" binding:"gt=0"`
+		Lang     string `form:"lang,default=en"`
+	}{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(400, "参数错误")
+		return
+	}
+
+	list, count, err := s.stories.List(time.Now().Add(-time.Duration(req.Hours)*time.Hour), req.Page, req.PageSize)
+	if err != nil {
+		c.Error(500, "获取事件列表失败")
+		return
+	}
+
+	tokens := make([]string, 0, len(list))
+	for _, story := range list {
+		tokens = append(tokens, story.Token)
+	}
+	articles, err := s.stories.Articles(tokens...)
+	if err != nil {
+		c.Error(500, "获取事件列表失败")
+		return
+	}
+
+	items := make([]*models.Article, 0)
+	for _, list := range articles {
+		items = append(items, list...)
+	}
+	s.localize(req.Lang, items...)
+
+	stories := make([]storyInfo, 0, len(list))
+	for _, story := range list {
+		stories = append(stories, newStoryInfo(story, articles[story.Token], req.Lang))
+	}
+
+	c.Pager(int(count), req.Page, req.PageSize, stories)
+}
+
+// StoryHandler 事件详情
+func (s *NewsService) StoryHandler(c *utils.ApiContext) {
+	token := c.Param("token")
+	lang := c.DefaultPostForm("lang", "en")
+
+	story, err := s.stories.Get(token)
+	if err != nil {
+		c.Error(404, "资源未找到")
+		return
+	}
+
+	articles, err := s.stories.Articles(token)
+	if err != nil {
+		c.Error(500, "获取事件文章失败")
+		return
+	}
+
+	s.localize(lang, articles[token]...)
+
+	c.Ok(newStoryInfo(story, articles[token], lang))
+}
+
+// TopicListHandler 主题列表
+func (s *NewsService) TopicListHandler(c *utils.ApiContext) {
+	req := struct {
+		Lang string `form:"lang,default=en"`
+	}{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(400, "参数错误")
+		return
+	}
+
+	topics := make([]topicInfo, 0)
+	for _, t := range topic.Topics() {
+		topics = append(topics, topicInfo{
+			Slug: t.Slug,
+			Name: t.GetNameByLang(req.Lang),
+		})
+	}
+
+	c.Ok(topics)
+}
+
+// CategoryListHandler 分类列表
+func (s *NewsService) CategoryListHandler(c *utils.ApiContext) {
+	req := struct {
+		Lang string `form:"lang,default=en"`
+	}{}
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(400, "参数错误")
+		return
+	}
+
+	list := s.taxonomy.Categories(true)
+	categories := make([]categoryInfo, 0, len(list))
+	for _, category := range list {
+		categories = append(categories, categoryInfo{
+			Slug: category.Slug,
+			Name: category.GetNameByLang(req.Lang),
+			Sort: category.Sort,
+		})
+	}
+
+	c.Ok(categories)
+}
+
+// CategoryRuleListHandler 来源网站栏目映射规则
+func (s *NewsService) CategoryRuleListHandler(c *utils.ApiContext) {
+	from := c.PostForm("from")
+	c.Ok(s.taxonomy.Rules(from))
+}
+
+// 分类信息
+type categoryInfo struct {
+	Slug models.CategoryTypes `json:"slug"`
+	Name string               `json:"name"`
+	Sort int                  `json:"sort"`
+}
+
+// 事件信息
+type storyInfo struct {
+	Token          string        `json:"token"`
+	Title          string        `json:"title"`
+	Origins        []string      `json:"origins"`
+	Sources        int           `json:"sources"`
+	Articles       int           `json:"articles"`
+	Datetime       string        `json:"datetime"`
+	Article        *articleInfo  `json:"article"`
+	AlsoReportedBy []articleInfo `json:"also_reported_by"`
+}
+
+func newStoryInfo(story *models.Story, articles []*models.Article, lang string) storyInfo {
+	info := storyInfo{
+		Token:          story.Token,
+		Title:          story.GetTitleByLang(lang),
+		Origins:        story.Origins,
+		Sources:        story.Sources,
+		Articles:       story.Articles,
+		Datetime:       story.LastTime.Format("2006-01-02 15:04:05"),
+		AlsoReportedBy: make([]articleInfo, 0, len(articles)),
+	}
+	for _, article := range articles {
+		item := newArticleInfo(article, lang)
+		if article.Token == story.ArticleToken {
+			info.Article = &item
+		} else {
+			info.AlsoReportedBy = append(info.AlsoReportedBy, item)
+		}
+	}
+
+	// 事件标题只有英文和中文，其他语言使用主文章的译文
+	if lang = models.NormalizeLang(lang); lang != models.LangEN && !models.IsChinese(lang) && info.Article != nil {
+		info.Title = info.Article.Title
+	}
+
+	return info
+}
+
+// 主题信息
+type topicInfo struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
 // 文章信息
 type articleInfo struct {
-	From     string `json:"from"`
-	Datetime string `json:"datetime"`
-	Title    string `json:"title"`
-	Link     string `json:"link"`
-	Author   string `json:"author"`
-	Image    string `json:"image"`
-	Token    string `json:"token"`
-	Abstract string `json:"abstract"`
+	From              string                 `json:"from"`
+	Categories        []models.CategoryTypes `json:"categories"`
+	Coins             []models.Coin          `json:"coins"`
+	Tags              []string               `json:"tags"`
+	Topics            []string               `json:"topics"`
+	Story             string                 `json:"story"`
+	AlsoReportedBy    []string               `json:"also_reported_by"`
+	Datetime          string                 `json:"datetime"`
+	Title             string                 `json:"title"`
+	Link              string                 `json:"link"`
+	Author            string                 `json:"author"`
+	Image             string                 `json:"image"`
+	Thumbnail         string                 `json:"thumbnail"`
+	ImageHash         string                 `json:"image_hash"`
+	Token             string                 `json:"token"`
+	Abstract          string                 `json:"abstract"`
+	AbstractGenerated bool                   `json:"abstract_generated"` // 简介由大模型生成
+}
+
+// collapseStories 同一事件的文章只展示第一篇，其他文章的来源作为“其他来源报道”，只为展示的文章加载译文。
+// 主页列表按事件分页，合并后的数量与分页一致
+func (s *NewsService) collapseStories(list []*models.Article, lang string) []articleInfo {
+	shown := make([]*models.Article, 0, len(list))
+	reportedBy := make([][]string, 0, len(list))
+	stories := make(map[string]int)
+	for _, article := range list {
+		if index, ok := stories[article.Story]; ok && article.Story != "" {
+			if !slices.Contains(reportedBy[index], article.From) {
+				reportedBy[index] = append(reportedBy[index], article.From)
+			}
+			continue
+		}
+
+		stories[article.Story] = len(shown)
+		shown = append(shown, article)
+		reportedBy = append(reportedBy, nil)
+	}
+	s.localize(lang, shown...)
+
+	articles := make([]articleInfo, 0, len(shown))
+	for i, article := range shown {
+		info := newArticleInfo(article, lang)
+		info.AlsoReportedBy = reportedBy[i]
+		articles = append(articles, info)
+	}
+
+	return articles
 }
 
 func newArticleInfo(article *models.Article, lang string) articleInfo {
 	return articleInfo{
-		From:     article.From,
-		Datetime: article.PubDate.Time.Format("2006-01-02 15:04:05"),
-		Title:    article.GetTitleByLang(lang),
-		Link:     article.Link,
-		Author:   article.Author,
-		Image:    article.Image,
-		Token:    article.Token,
-		Abstract: article.GetAbstractByLang(lang),
+		From:              article.From,
+		Categories:        article.Categories,
+		Coins:             article.Coins,
+		Tags:              article.Tags,
+		Topics:            article.Topics,
+		Story:             article.Story,
+		Datetime:          article.PubDate.Time.Format("2006-01-02 15:04:05"),
+		Title:             article.GetTitleByLang(lang),
+		Link:              article.Link,
+		Author:            article.Author,
+		Image:             article.Image,
+		Thumbnail:         article.Thumbnail,
+		ImageHash:         article.ImageHash,
+		Token:             article.Token,
+		Abstract:          article.GetAbstractByLang(lang),
+		AbstractGenerated: article.AbstractGenerated,
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"news/src/models"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -17,11 +18,22 @@ const (
 	NewsOriginZSetKey     = "news:origin:%s"           // 指定网站文章集合
 	NewsTokenKey          = "news:tokens:%s"           // 文章内容信息
 	NewsOriginsSetKey     = "news:origins:category:%s" // 类别来源网址列表
+	NewsTopicZSetKey      = "news:topic:%s"            // 指定主题文章集合
 	NewsAllTokensZSetKey  = "news:all:tokens"          // 所有文章 token 列表
 	TempNewsOriginZSetKey = "temp:news:origin:%s"      // 临时多网站合集
+	TempNewsTopicZSetKey  = "temp:news:topic:%s:%s"    // 临时分类主题交集
 
-	CoinSlugsListKey = "coin:slugs:%s"      // 指定币文章列表
+	// 主页按事件分组的列表，成员为事件标识，没有事件的文章为文章 token，分数为组内最新文章的分数
+	NewsAllGroupsZSetKey      = "news:groups:all"              // 所有事件
+	NewsCategoryGroupsZSetKey = "news:groups:category:%s"      // 指定分类事件
+	NewsTopicGroupsZSetKey    = "news:groups:topic:%s"         // 指定主题事件
+	NewsGroupTokensZSetKey    = "news:groups:tokens:%s"        // 事件中的文章
+	TempNewsTopicGroupsKey    = "temp:news:groups:topic:%s:%s" // 临时分类主题事件交集
+
+	CoinNewsZSetKey  = "coin:news:slug:%s"  // 指定币种文章集合
 	CoinNewsTokenKey = "coin:news:token:%s" // 币种文章信息
+	CoinSlugsZSetKey = "coin:slugs"         // 币种列表，按最新文章时间排序
+	CoinInfoHashKey  = "coin:info"          // 币种信息
 
 	DataVersionZSetKye = "data:versions" // 数据版本列表
 )
@@ -95,21 +107,82 @@ func (s *RedisStorage) Save(article *models.Article) error {
 	ctx := context.Background()
 
 	article.Token = article.GenToken()
+	article.AddCategory(article.Category)
 	score := article.GetScore()
-	// 保存文章信息
+
+	// 合并已保存的分类
 	key := s.sKey(NewsTokenKey, article.Token)
+	existingArticle := &models.Article{}
+	if err := s.client.Get(ctx, key).Scan(existingArticle); err == nil {
+		article.AddCategory(existingArticle.Categories...)
+		article.AddCategory(existingArticle.Category)
+		article.AddCoin(existingArticle.Coins...)
+		article.AddTag(existingArticle.Tags...)
+	}
+
+	// 保存文章信息
 	if err := s.client.Set(ctx, key, article, 0).Err(); err != nil {
 		return err
 	}
 
-	// 保存到分类集合
-	key = s.sKey(NewsCategoryZSetKey, article.Category)
-	if err := s.client.ZAdd(ctx, key, redis.Z{
+	if err := s.saveCoins(ctx, article, score); err != nil {
+		return err
+	}
+
+	for _, category := range article.Categories {
+		// 保存到分类集合
+		key = s.sKey(NewsCategoryZSetKey, category)
+		if err := s.client.ZAdd(ctx, key, redis.Z{
+			Member: article.Token,
+			Score:  score,
+		}).Err(); err != nil {
+			return err
+		}
+
+		// 保存类别来源列表
+		key = s.sKey(NewsOriginsSetKey, category)
+		if err := s.client.SAdd(ctx, key, article.From).Err(); err != nil {
+			return err
+		}
+	}
+
+	// 保存到主题集合
+	for _, topic := range article.Topics {
+		key = s.sKey(NewsTopicZSetKey, topic)
+		if err := s.client.ZAdd(ctx, key, redis.Z{
+			Member: article.Token,
+			Score:  score,
+		}).Err(); err != nil {
+			return err
+		}
+	}
+
+	// 保存文章所属事件，事件按最新文章排序
+	group := article.Story
+	if group == "" {
+		group = article.Token
+	}
+	if err := s.client.ZAdd(ctx, s.sKey(NewsGroupTokensZSetKey, group), redis.Z{
 		Member: article.Token,
 		Score:  score,
 	}).Err(); err != nil {
 		return err
 	}
+	groupKeys := []string{s.sKey(NewsAllGroupsZSetKey)}
+	for _, category := range article.Categories {
+		groupKeys = append(groupKeys, s.sKey(NewsCategoryGroupsZSetKey, category))
+	}
+	for _, topic := range article.Topics {
+		groupKeys = append(groupKeys, s.sKey(NewsTopicGroupsZSetKey, topic))
+	}
+	for _, key := range groupKeys {
+		if err := s.client.ZAddGT(ctx, key, redis.Z{
+			Member: group,
+			Score:  score,
+		}).Err(); err != nil {
+			return err
+		}
+	}
 
 	// 保存到网站集合
 	key = s.sKey(NewsOriginZSetKey, article.From)
@@ -120,12 +193,6 @@ func (s *RedisStorage) Save(article *models.Article) error {
 		return err
 	}
 
-	// 保存类别来源列表
-	key = s.sKey(NewsOriginsSetKey, article.Category)
-	if err := s.client.SAdd(ctx, key, article.From).Err(); err != nil {
-		return err
-	}
-
 	// 保存所有文章 token 列表
 	key = s.sKey(NewsAllTokensZSetKey)
 	if err := s.client.ZAdd(ctx, key, redis.Z{
@@ -142,42 +209,126 @@ func (s *RedisStorage) SaveCoin(article *models.Article) error {
 	ctx := context.Background()
 
 	article.Token = article.GenToken()
-	// 保存币种文章列表
-	key := s.sKey(CoinSlugsListKey, article.Category)
-	if err := s.client.LPush(ctx, key, article.Token).Err(); err != nil {
-		return err
+	score := article.GetScore()
+
+	// 合并已关联的币种
+	key := s.sKey(CoinNewsTokenKey, article.Token)
+	existingArticle := &models.Article{}
+	if err := s.client.Get(ctx, key).Scan(existingArticle); err == nil {
+		article.AddCoin(existingArticle.Coins...)
 	}
 
 	// 保存文章信息
-	key = s.sKey(CoinNewsTokenKey, article.Token)
 	if err := s.client.Set(ctx, key, article, 0).Err(); err != nil {
 		return err
 	}
 
+	// 文章已收录时同步关联的币种
+	key = s.sKey(NewsTokenKey, article.Token)
+	newsArticle := &models.Article{}
+	if err := s.client.Get(ctx, key).Scan(newsArticle); err == nil {
+		newsArticle.AddCoin(article.Coins...)
+		if err := s.client.Set(ctx, key, newsArticle, 0).Err(); err != nil {
+			return err
+		}
+	}
+
+	return s.saveCoins(ctx, article, score)
+}
+
+// saveCoins 保存文章到关联币种的文章集合，并更新币种列表和币种信息
+func (s *RedisStorage) saveCoins(ctx context.Context, article *models.Article, score float64) error {
+	for _, coin := range article.Coins {
+		// 保存到币种文章集合
+		key := s.sKey(CoinNewsZSetKey, coin.Slug)
+		if err := s.client.ZAdd(ctx, key, redis.Z{
+			Member: article.Token,
+			Score:  score,
+		}).Err(); err != nil {
+			return err
+		}
+
+		// 保存币种列表
+		key = s.sKey(CoinSlugsZSetKey)
+		if err := s.client.ZAddGT(ctx, key, redis.Z{
+			Member: coin.Slug,
+			Score:  score,
+		}).Err(); err != nil {
+			return err
+		}
+
+		// 保存币种信息，合并已保存的信息
+		key = s.sKey(CoinInfoHashKey)
+		existingCoin := &models.Coin{}
+		if err := s.client.HGet(ctx, key, coin.Slug).Scan(existingCoin); err == nil {
+			coin.Merge(existingCoin)
+		}
+		if err := s.client.HSet(ctx, key, coin.Slug, coin.Bytes()).Err(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (s *RedisStorage) GetHomeList(category string, page, size int) ([]*models.Article, int64, error) {
-	var (
-		articles []*models.Article
-		count    int64
-	)
+// getCoinArticle 获取币种文章信息
+func (s *RedisStorage) getCoinArticle(token string) (*models.Article, error) {
+	article := &models.Article{}
 
-	key := s.sKey(NewsAllTokensZSetKey)
-	if category != "" {
-		key = s.sKey(NewsCategoryZSetKey, category)
+	key := s.sKey(CoinNewsTokenKey, token)
+	if err := s.client.Get(context.Background(), key).Scan(article); err != nil {
+		return s.Get(token) // 其他来源识别出币种的文章
 	}
 
+	return article, nil
+}
+
+func (s *RedisStorage) GetCoinList(page, size int) ([]*models.Coin, int64, error) {
+	ctx := context.Background()
 	start := int64((page - 1) * size)
 	stop := int64(page*size) - 1
+
+	key := s.sKey(CoinSlugsZSetKey)
+	slugs, err := s.client.ZRevRange(ctx, key, start, stop).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	count := s.client.ZCard(ctx, key).Val()
+	if len(slugs) == 0 {
+		return []*models.Coin{}, count, nil
+	}
+
+	values, err := s.client.HMGet(ctx, s.sKey(CoinInfoHashKey), slugs...).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	coins := make([]*models.Coin, 0, len(values))
+	for i, value := range values {
+		coin := &models.Coin{Slug: slugs[i]}
+		if data, ok := value.(string); ok {
+			_ = coin.UnmarshalBinary([]byte(data))
+		}
+		coins = append(coins, coin)
+	}
+
+	return coins, count, nil
+}
+
+func (s *RedisStorage) GetListByCoin(slug string, page, size int) ([]*models.Article, int64, error) {
+	start := int64((page - 1) * size)
+	stop := int64(page*size) - 1
+
+	key := s.sKey(CoinNewsZSetKey, slug)
 	tokens, err := s.client.ZRevRange(context.Background(), key, start, stop).Result()
 	if err != nil {
 		return nil, 0, err
 	}
 
-	count = s.client.ZCard(context.Background(), key).Val()
+	count := s.client.ZCard(context.Background(), key).Val()
+	articles := make([]*models.Article, 0, len(tokens))
 	for _, token := range tokens {
-		if article, err := s.Get(token); err == nil {
+		if article, err := s.getCoinArticle(token); err == nil {
 			articles = append(articles, article)
 		}
 	}
@@ -185,6 +336,87 @@ func (s *RedisStorage) GetHomeList(category string, page, size int) ([]*models.A
 	return articles, count, nil
 }
 
+func (s *RedisStorage) GetHomeList(category, topic string, page, size int) ([]*models.Article, int64, error) {
+	ctx := context.Background()
+
+	// tokensKey 为列表中的文章，groupsKey 为列表中的事件，按事件分页
+	tokensKey, groupsKey := s.sKey(NewsAllTokensZSetKey), s.sKey(NewsAllGroupsZSetKey)
+	switch {
+	case category != "" && topic != "":
+		// 分类和主题的交集，短时间缓存
+		tokensKey = s.sKey(TempNewsTopicZSetKey, category, topic)
+		groupsKey = s.sKey(TempNewsTopicGroupsKey, category, topic)
+		if s.client.Exists(ctx, tokensKey, groupsKey).Val() < 2 {
+			for key, keys := range map[string][]string{
+				tokensKey: {s.sKey(NewsCategoryZSetKey, category), s.sKey(NewsTopicZSetKey, topic)},
+				groupsKey: {s.sKey(NewsCategoryGroupsZSetKey, category), s.sKey(NewsTopicGroupsZSetKey, topic)},
+			} {
+				if err := s.client.ZInterStore(ctx, key, &redis.ZStore{Keys: keys, Aggregate: "MAX"}).Err(); err != nil {
+					return nil, 0, err
+				}
+				s.client.Expire(ctx, key, time.Minute)
+			}
+		}
+	case category != "":
+		tokensKey, groupsKey = s.sKey(NewsCategoryZSetKey, category), s.sKey(NewsCategoryGroupsZSetKey, category)
+	case topic != "":
+		tokensKey, groupsKey = s.sKey(NewsTopicZSetKey, topic), s.sKey(NewsTopicGroupsZSetKey, topic)
+	}
+
+	count, err := s.client.ZCard(ctx, groupsKey).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	start := int64((page - 1) * size)
+	groups, err := s.client.ZRevRange(ctx, groupsKey, start, start+int64(size)-1).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 返回本页各事件在列表中的所有文章
+	articles := make([]*models.Article, 0, len(groups))
+	for _, group := range groups {
+		tokens, err := s.groupTokens(ctx, group, tokensKey)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, token := range tokens {
+			if article, err := s.Get(token); err == nil {
+				articles = append(articles, article)
+			}
+		}
+	}
+
+	return articles, count, nil
+}
+
+// groupTokens 事件中属于列表的文章，按时间倒序
+func (s *RedisStorage) groupTokens(ctx context.Context, group, tokensKey string) ([]string, error) {
+	tokens, err := s.client.ZRevRange(ctx, s.sKey(NewsGroupTokensZSetKey, group), 0, -1).Result()
+	if err != nil || len(tokens) == 0 {
+		return tokens, err
+	}
+
+	// 没有发布时间的文章分数为 0，按是否存在判断
+	cmds := make([]*redis.FloatCmd, len(tokens))
+	if _, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, token := range tokens {
+			cmds[i] = pipe.ZScore(ctx, tokensKey, token)
+		}
+		return nil
+	}); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	list := make([]string, 0, len(tokens))
+	for i, token := range tokens {
+		if cmds[i].Err() == nil {
+			list = append(list, token)
+		}
+	}
+
+	return list, nil
+}
+
 func (s *RedisStorage) GetReadList(origins []string, category string) (map[string][]*models.Article, error) {
 	tempUnionKey := fmt.Sprintf(TempNewsOriginZSetKey, strings.Join(origins, "."))
 	keys := make([]string, 0, 10)
@@ -280,7 +512,7 @@ func (s *RedisStorage) GetOriginsByCategory(category string) ([]string, error) {
 	return origins, nil
 }
 
-func (s *RedisStorage) NewsSearch(keyword string, page, size int) ([]*models.Article, int64, error) {
+func (s *RedisStorage) NewsSearch(keyword, topic string, page, size int) ([]*models.Article, int64, error) {
 	return nil, 0, errors.New("not implemented")
 }
 
@@ -16,12 +16,14 @@ type Strategy interface {
 	Get(token string) (*models.Article, error)
 	Save(article *models.Article) error
 	SaveCoin(article *models.Article) error
-	GetHomeList(category string, page, size int) ([]*models.Article, int64, error)
+	GetHomeList(category, topic string, page, size int) ([]*models.Article, int64, error)
 	GetReadList(origin []string, category string) (map[string][]*models.Article, error)
 	GetListByCategory(category string) ([]*models.Article, error)
 	GetListByOrigin(origin string, page, size int) ([]*models.Article, int64, error)
 	GetOriginsByCategory(category string) ([]string, error)
-	NewsSearch(keyword string, page, size int) ([]*models.Article, int64, error)
+	GetCoinList(page, size int) ([]*models.Coin, int64, error)
+	GetListByCoin(slug string, page, size int) ([]*models.Article, int64, error)
+	NewsSearch(keyword, topic string, page, size int) ([]*models.Article, int64, error)
 	Restore() error
 }
 
@@ -136,14 +138,14 @@ func (s *Service) SaveCoin(article *models.Article) error {
 	return errors.Join(errs...)
 }
 
-func (s *Service) GetHomeList(category string, page, size int) ([]*models.Article, int64) {
+func (s *Service) GetHomeList(category, topic string, page, size int) ([]*models.Article, int64) {
 	var (
 		articles []*models.Article
 		count    int64
 		err      error
 	)
 	s.fetch(func(store Strategy) bool {
-		if articles, count, err = store.GetHomeList(category, page, size); err != nil {
+		if articles, count, err = store.GetHomeList(category, topic, page, size); err != nil {
 			return false
 		}
 
@@ -218,14 +220,48 @@ func (s *Service) GetOriginsByCategory(category string) ([]string, error) {
 	return origins, err
 }
 
-func (s *Service) NewsSearch(keyword string, page, size int) ([]*models.Article, int64) {
+func (s *Service) GetCoinList(page, size int) ([]*models.Coin, int64, error) {
+	var (
+		coins []*models.Coin
+		count int64
+		err   error
+	)
+	s.fetch(func(store Strategy) bool {
+		if coins, count, err = store.GetCoinList(page, size); err != nil {
+			return false
+		}
+
+		return true
+	})
+
+	return coins, count, err
+}
+
+func (s *Service) GetListByCoin(slug string, page, size int) ([]*models.Article, int64, error) {
+	var (
+		articles []*models.Article
+		count    int64
+		err      error
+	)
+	s.fetch(func(store Strategy) bool {
+		if articles, count, err = store.GetListByCoin(slug, page, size); err != nil {
+			return false
+		}
+
+		return true
+	})
+
+	return articles, count, err
+}
+
+func (s *Service) NewsSearch(keyword, topic string, page, size int) ([]*models.Article, int64) {
 	var (
 		articles []*models.Article
 		count    int64
 		err      error
 	)
 	s.fetch(func(store Strategy) bool {
-		if articles, count, err = store.NewsSearch(keyword, page, size); err != nil {
+		if articles, count, err = store.NewsSearch(keyword, topic, page, size); err != nil {
 			return false
 		}
 
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"gorm.io/gorm"
+	"news/src/models"
+	"time"
+)
+
+// Stories 多来源事件查询
+type Stories struct {
+	db *gorm.DB
+}
+
+func NewStories() *Stories {
+	return &Stories{
+		db: models.DB,
+	}
+}
+
+// List 指定时间内的事件，按报道来源数量排序
+func (s *Stories) List(since time.Time, page, size int) ([]*models.Story, int64, error) {
+	var (
+		stories = make([]*models.Story, 0, size)
+		count   int64
+	)
+
+	query := s.db.Model(&models.Story{}).Where("last_time > ?", since)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("sources DESC, last_time DESC").Offset((page - 1) * size).Limit(size).Find(&stories).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return stories, count, nil
+}
+
+// Get 获取事件信息
+func (s *Stories) Get(token string) (*models.Story, error) {
+	story := &models.Story{}
+	if err := s.db.Where("token = ?", token).First(story).Error; err != nil {
+		return nil, err
+	}
+
+	return story, nil
+}
+
+// Articles 事件中的文章，按事件分组
+func (s *Stories) Articles(tokens ...string) (map[string][]*models.Article, error) {
+	articles := make([]*models.Article, 0)
+	if err := s.db.Where("story IN ?", tokens).Order("pub_date").Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	list := make(map[string][]*models.Article)
+	for _, article := range articles {
+		list[article.Story] = append(list[article.Story], article)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"gorm.io/gorm"
+	"news/src/logger"
+	"news/src/models"
+	"sync"
+	"time"
+)
+
+// Taxonomy 分类体系，缓存数据库中的分类和来源栏目映射规则
+type Taxonomy struct {
+	db *gorm.DB
+
+	categories []*models.Category
+	rules      map[string]map[models.CategoryTypes]models.CategoryTypes // from -> section -> category
+	loaded     time.Time
+	ttl        time.Duration
+	lock       sync.RWMutex
+}
+
+func NewTaxonomy() *Taxonomy {
+	return &Taxonomy{
+		db:  models.DB,
+		ttl: 5 * time.Minute,
+	}
+}
+
+// refresh 缓存过期后重新加载
+func (t *Taxonomy) refresh() {
+	t.lock.RLock()
+	expired := time.Since(t.loaded) > t.ttl
+	t.lock.RUnlock()
+	if !expired {
+		return
+	}
+
+	categories := make([]*models.Category, 0)
+	if err := t.db.Order("sort, id").Find(&categories).Error; err != nil {
+		logger.Errorf("Failed to load categories: %s", err)
+		return
+	}
+
+	list := make([]*models.CategoryRule, 0)
+	if err := t.db.Find(&list).Error; err != nil {
+		logger.Errorf("Failed to load category rules: %s", err)
+		return
+	}
+
+	rules := make(map[string]map[models.CategoryTypes]models.CategoryTypes)
+	for _, rule := range list {
+		if _, ok := rules[rule.From]; !ok {
+			rules[rule.From] = make(map[models.CategoryTypes]models.CategoryTypes)
+		}
+		rules[rule.From][rule.Section] = rule.Category
+	}
+
+	t.lock.Lock()
+	t.categories = categories
+	t.rules = rules
+	t.loaded = time.Now()
+	t.lock.Unlock()
+}
+
+// Categories 分类列表，visible 为 true 时只返回可见分类
+func (t *Taxonomy) Categories(visible bool) []*models.Category {
+	t.refresh()
+
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	categories := make([]*models.Category, 0, len(t.categories))
+	for _, category := range t.categories {
+		if visible && !category.Visible {
+			continue
+		}
+		categories = append(categories, category)
+	}
+
+	return categories
+}
+
+// Rules 来源网站栏目映射规则
+func (t *Taxonomy) Rules(from string) []*models.CategoryRule {
+	t.refresh()
+
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	rules := make([]*models.CategoryRule, 0)
+	for origin, sections := range t.rules {
+		if from != "" && origin != from && origin != models.AnyOrigin {
+			continue
+		}
+		for section, category := range sections {
+			rules = append(rules, &models.CategoryRule{
+				From:     origin,
+				Section:  section,
+				Category: category,
+			})
+		}
+	}
+
+	return rules
+}
+
+// Map 将来源网站栏目映射为分类，优先使用来源网站规则，其次通用规则，没有规则时保留栏目名称
+func (t *Taxonomy) Map(from string, section models.CategoryTypes) (models.CategoryTypes, bool) {
+	t.refresh()
+
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	for _, origin := range []string{from, models.AnyOrigin} {
+		if category, ok := t.rules[origin][section]; ok {
+			return category, category != ""
+		}
+	}
+
+	return section, true
+}
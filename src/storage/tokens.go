@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"news/src/logger"
+	"news/src/models"
+	"news/src/utils"
+)
+
+// MigrateTokens 将 MySQL 迁移中变更的文章标识同步到当前数据版本的 Redis 和 Elasticsearch
+func MigrateTokens() error {
+	version, err := NewRedisStorage(0).GetVersion()
+	if err != nil {
+		return err
+	}
+
+	return NewServiceWithVersion(version).MigrateTokens()
+}
+
+// MigrateTokens 同步未处理的标识变更，全部存储同步成功后标记为已同步
+func (s *Service) MigrateTokens() error {
+	m, err := s.mysql()
+	if err != nil {
+		return err
+	}
+
+	migrations := make([]*models.TokenMigration, 0)
+	if err := m.DB.Where("synced = ?", false).Order("id").Find(&migrations).Error; err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, migration := range migrations {
+		var failed bool
+		for _, store := range s.storages {
+			switch store := store.(type) {
+			case *RedisStorage:
+				err = store.MigrateToken(migration.OldToken, migration.NewToken)
+			case *ElasticsearchStorage:
+				err = store.MigrateToken(migration.OldToken, migration.NewToken)
+			default:
+				continue
+			}
+			if err != nil {
+				failed = true
+				errs = append(errs, fmt.Errorf("migrate token %s: %w", migration.OldToken, err))
+			}
+		}
+		if failed {
+			continue
+		}
+
+		if err := m.DB.Model(migration).Update("synced", true).Error; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(migrations) > 0 {
+		logger.Infof("Synced %d token migrations", len(migrations))
+	}
+
+	return errors.Join(errs...)
+}
+
+// MigrateToken 将文章从旧标识移到新标识，移出旧标识所在的集合后按新标识重新保存，旧标识不存在时忽略
+func (s *RedisStorage) MigrateToken(oldToken, newToken string) error {
+	ctx := context.Background()
+
+	if err := s.migrateCoinToken(ctx, oldToken, newToken); err != nil {
+		return err
+	}
+
+	key := s.sKey(NewsTokenKey, oldToken)
+	article := &models.Article{}
+	if err := s.client.Get(ctx, key).Scan(article); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}
+
+	keys := []string{
+		s.sKey(NewsAllTokensZSetKey),
+		s.sKey(NewsOriginZSetKey, article.From),
+	}
+	for _, category := range article.Categories {
+		keys = append(keys, s.sKey(NewsCategoryZSetKey, category))
+	}
+	for _, topic := range article.Topics {
+		keys = append(keys, s.sKey(NewsTopicZSetKey, topic))
+	}
+	for _, coin := range article.Coins {
+		keys = append(keys, s.sKey(CoinNewsZSetKey, coin.Slug))
+	}
+
+	// 没有事件的文章以自身标识分组，旧标识的分组一并移除
+	group := article.Story
+	if group == "" {
+		group = oldToken
+	}
+	keys = append(keys, s.sKey(NewsGroupTokensZSetKey, group))
+	if group == oldToken {
+		keys = append(keys, s.sKey(NewsAllGroupsZSetKey))
+		for _, category := range article.Categories {
+			keys = append(keys, s.sKey(NewsCategoryGroupsZSetKey, category))
+		}
+		for _, topic := range article.Topics {
+			keys = append(keys, s.sKey(NewsTopicGroupsZSetKey, topic))
+		}
+	}
+	for _, k := range keys {
+		if err := s.client.ZRem(ctx, k, oldToken).Err(); err != nil {
+			return err
+		}
+	}
+	if err := s.client.Del(ctx, key).Err(); err != nil {
+		return err
+	}
+
+	article.Token = newToken
+	article.CanonicalURL = utils.CanonicalURL(article.Link)
+	return s.Save(article)
+}
+
+// migrateCoinToken 将币种文章移到新标识
+func (s *RedisStorage) migrateCoinToken(ctx context.Context, oldToken, newToken string) error {
+	key := s.sKey(CoinNewsTokenKey, oldToken)
+	article := &models.Article{}
+	if err := s.client.Get(ctx, key).Scan(article); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}
+
+	for _, coin := range article.Coins {
+		if err := s.client.ZRem(ctx, s.sKey(CoinNewsZSetKey, coin.Slug), oldToken).Err(); err != nil {
+			return err
+		}
+	}
+	if err := s.client.Del(ctx, key).Err(); err != nil {
+		return err
+	}
+
+	article.Token = newToken
+	article.CanonicalURL = utils.CanonicalURL(article.Link)
+	return s.SaveCoin(article)
+}
+
+// MigrateToken 将旧标识的文档按新标识重新索引后删除旧文档，旧文档不存在时忽略
+func (s *ElasticsearchStorage) MigrateToken(oldToken, newToken string) error {
+	resp, err := s.client.Get(s.index, oldToken)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.IsError() {
+		return fmt.Errorf("get document %s: %s", oldToken, resp)
+	}
+
+	var body struct {
+		Source *models.Article `json:"_source"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	if body.Source == nil {
+		return nil
+	}
+
+	article := body.Source
+	article.Token = newToken
+	article.CanonicalURL = utils.CanonicalURL(article.Link)
+	if err := s.put(article); err != nil {
+		return err
+	}
+
+	resp, err = s.client.Delete(s.index, oldToken)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("delete document %s: %s", oldToken, resp)
+	}
+
+	return nil
+}
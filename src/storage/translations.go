@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"news/src/config"
+	"news/src/logger"
+	"news/src/models"
+	"news/src/translator"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// queueSize 后台翻译队列长度，队列已满时丢弃，之后的请求重新排队
+	queueSize = 100
+
+	// batchSize 后台翻译每批文章数
+	batchSize = 10
+)
+
+// translationJob 后台翻译任务
+type translationJob struct {
+	lang     string
+	articles []*models.Article
+}
+
+// Translations 文章英文和中文以外的译文，配置的语言在抓取时翻译，允许按需翻译的语言在首次请求时后台翻译并保存
+type Translations struct {
+	db         *gorm.DB
+	translator translator.Translator
+
+	queue   chan translationJob
+	pending sync.Map
+	once    sync.Once
+}
+
+func NewTranslations(t translator.Translator) *Translations {
+	return &Translations{
+		db:         models.DB,
+		translator: t,
+	}
+}
+
+// Load 加载已保存的指定语言译文，返回没有译文的文章。繁体中文由简体中文转换，不需要翻译
+func (t *Translations) Load(lang string, articles ...*models.Article) ([]*models.Article, error) {
+	lang = models.NormalizeLang(lang)
+	if lang == models.LangEN || models.IsChinese(lang) || len(articles) == 0 {
+		return nil, nil
+	}
+
+	tokens := make([]string, 0, len(articles))
+	for _, article := range articles {
+		tokens = append(tokens, article.GenToken())
+	}
+	rows := make([]*models.ArticleTranslation, 0, len(articles))
+	if err := t.db.Where("token IN ? AND lang = ?", tokens, lang).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	translations := make(map[string]*models.ArticleTranslation, len(rows))
+	for _, row := range rows {
+		translations[row.Token] = row
+	}
+
+	missing := make([]*models.Article, 0)
+	for _, article := range articles {
+		if row, ok := translations[article.GenToken()]; ok {
+			article.SetTranslation(row)
+		} else {
+			missing = append(missing, article)
+		}
+	}
+
+	return missing, nil
+}
+
+// Apply 加载指定语言译文，没有译文的文章由英文标题和简介翻译后保存
+func (t *Translations) Apply(lang string, articles ...*models.Article) error {
+	missing, err := t.Load(lang, articles...)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+	lang = models.NormalizeLang(lang)
+
+	// 标题和简介一起翻译
+	texts := make([]string, 0, len(missing)*2)
+	for _, article := range missing {
+		texts = append(texts, article.Title)
+	}
+	abstracts := make(map[int]int)
+	for i, article := range missing {
+		if article.Abstract != "" {
+			abstracts[i] = len(texts)
+			texts = append(texts, article.Abstract)
+		}
+	}
+
+	results, err := translator.Results(context.Background(), t.translator, lang, texts...)
+	if len(results) != len(texts) {
+		if err == nil {
+			err = translator.ErrMismatch
+		}
+		return err
+	}
+
+	rows := make([]*models.ArticleTranslation, 0, len(missing))
+	for i, article := range missing {
+		if results[i].Text == "" {
+			continue
+		}
+		row := &models.ArticleTranslation{
+			Token:      article.GenToken(),
+			Lang:       lang,
+			Title:      results[i].Text,
+			Translator: results[i].Translator,
+			CreateTime: time.Now(),
+			UpdateTime: time.Now(),
+		}
+		if j, ok := abstracts[i]; ok {
+			row.Abstract = results[j].Text
+		}
+		article.SetTranslation(row)
+		rows = append(rows, row)
+	}
+	if len(rows) > 0 {
+		if e := t.db.Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"title", "abstract", "translator", "update_time"}),
+		}).Create(&rows).Error; e != nil {
+			return e
+		}
+	}
+
+	return err
+}
+
+// Translatable 是否允许按需翻译该语言，包括抓取时翻译的语言和 [translate] on-demand 配置的语言
+func Translatable(lang string) bool {
+	lang = models.NormalizeLang(lang)
+	if lang == models.LangEN || models.IsChinese(lang) {
+		return false
+	}
+
+	c := config.Cfg.Translate
+	return slices.ContainsFunc(slices.Concat(c.Languages, c.OnDemand), func(l string) bool {
+		return models.NormalizeLang(l) == lang
+	})
+}
+
+// Queue 后台翻译没有译文的文章，不允许按需翻译的语言忽略。同一文章和语言只排队一次，队列已满时丢弃
+func (t *Translations) Queue(lang string, articles ...*models.Article) {
+	if !Translatable(lang) || len(articles) == 0 {
+		return
+	}
+	t.once.Do(func() {
+		t.queue = make(chan translationJob, queueSize)
+		go t.work()
+	})
+
+	lang = models.NormalizeLang(lang)
+	for batch := range slices.Chunk(articles, batchSize) {
+		job := translationJob{lang: lang}
+		for _, article := range batch {
+			token := article.GenToken()
+			if _, loaded := t.pending.LoadOrStore(lang+":"+token, true); loaded {
+				continue
+			}
+			// 复制翻译需要的字段，避免和请求同时修改文章
+			job.articles = append(job.articles, &models.Article{Token: token, Title: article.Title, Abstract: article.Abstract})
+		}
+		if len(job.articles) == 0 {
+			continue
+		}
+
+		select {
+		case t.queue <- job:
+		default:
+			logger.Warnf("Translation queue is full, dropped %d articles for %s", len(job.articles), lang)
+			t.done(job)
+		}
+	}
+}
+
+// work 按顺序执行后台翻译任务
+func (t *Translations) work() {
+	for job := range t.queue {
+		if err := t.Apply(job.lang, job.articles...); err != nil {
+			logger.Warnf("Failed to translate articles to %s: %s", job.lang, err)
+		}
+		t.done(job)
+	}
+}
+
+// done 任务结束后允许重新排队，翻译失败的文章在之后的请求中重试
+func (t *Translations) done(job translationJob) {
+	for _, article := range job.articles {
+		t.pending.Delete(job.lang + ":" + article.Token)
+	}
+}
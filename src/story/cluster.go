@@ -0,0 +1,279 @@
+package story
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"news/src/config"
+	"news/src/logger"
+	"news/src/models"
+	"news/src/utils"
+	"slices"
+	"sync"
+	"time"
+)
+
+// member 时间窗口内已聚合的文章，标题词频向量在加入时计算
+type member struct {
+	token    string
+	from     string
+	title    string
+	titleCN  string
+	vector   utils.Vector
+	vectorCN utils.Vector
+	lang     string
+	story    string
+	rank     int
+	time     time.Time
+	seq      int
+}
+
+// Clusterer 将不同来源网站报道同一事件的文章聚合为事件
+type Clusterer struct {
+	db        *gorm.DB
+	threshold float64
+	cross     float64
+	window    time.Duration
+	members   []*member
+	tokens    map[string]*member
+	terms     map[string]map[*member]struct{} // 词 -> 标题包含该词的文章，没有共同词的文章相似度为0，不需要比较
+	seq       int
+	lock      sync.Mutex
+}
+
+func NewClusterer() *Clusterer {
+	c := &Clusterer{
+		db:        models.DB,
+		threshold: config.Cfg.Story.Threshold,
+		cross:     config.Cfg.Story.CrossThreshold,
+		window:    time.Duration(config.Cfg.Story.Window) * time.Hour,
+		tokens:    make(map[string]*member),
+		terms:     make(map[string]map[*member]struct{}),
+	}
+	if c.threshold <= 0 {
+		c.threshold = 0.6
+	}
+	if c.cross <= 0 {
+		c.cross = c.threshold
+	}
+	if c.window <= 0 {
+		c.window = 72 * time.Hour
+	}
+	c.load()
+
+	return c
+}
+
+// load 加载时间窗口内已聚合的文章，并清理只有一个来源的事件
+func (c *Clusterer) load() {
+	if err := c.db.Where("sources < 2").Delete(&models.Story{}).Error; err != nil {
+		logger.Errorf("Failed to clean single source stories: %s", err)
+	}
+
+	articles := make([]*models.Article, 0)
+	err := c.db.Model(&models.Article{}).
+		Select("token", "from", "lang", "title", "title_ch", "image", "abstract", "story", "pub_date", "create_time").
+		Where("story <> '' AND create_time > ?", time.Now().Add(-c.window)).
+		Find(&articles).Error
+	if err != nil {
+		logger.Errorf("Failed to load story members: %s", err)
+		return
+	}
+
+	for _, article := range articles {
+		c.add(newMember(article, article.Story))
+	}
+	logger.Infof("Loaded %d story members", len(c.members))
+}
+
+func newMember(article *models.Article, story string) *member {
+	m := &member{
+		token:   article.Token,
+		from:    article.From,
+		title:   article.Title,
+		titleCN: article.TitleCN,
+		vector:  utils.NewVector(article.Title),
+		lang:    article.Lang,
+		story:   story,
+		time:    article.CreateTime,
+	}
+	if article.PubDate.Valid {
+		m.time = article.PubDate.Time
+	}
+	if m.time.IsZero() {
+		m.time = time.Now()
+	}
+	if article.TitleCN != "" {
+		m.vectorCN = utils.NewVector(article.TitleCN)
+	}
+
+	// 有配图、有简介的文章优先作为代表文章
+	if article.Image != "" {
+		m.rank += 2
+	}
+	if article.Abstract != "" {
+		m.rank++
+	}
+
+	return m
+}
+
+// add 加入文章并按标题中的词建立索引
+func (c *Clusterer) add(m *member) {
+	c.seq++
+	m.seq = c.seq
+	c.members = append(c.members, m)
+	c.tokens[m.token] = m
+	for _, v := range []utils.Vector{m.vector, m.vectorCN} {
+		for _, term := range v.Terms() {
+			if c.terms[term] == nil {
+				c.terms[term] = make(map[*member]struct{})
+			}
+			c.terms[term][m] = struct{}{}
+		}
+	}
+}
+
+// prune 移除时间窗口外的文章
+func (c *Clusterer) prune() {
+	since := time.Now().Add(-c.window)
+	members := c.members[:0]
+	for _, m := range c.members {
+		if m.time.After(since) {
+			members = append(members, m)
+			continue
+		}
+
+		if c.tokens[m.token] == m {
+			delete(c.tokens, m.token)
+		}
+		for _, v := range []utils.Vector{m.vector, m.vectorCN} {
+			for _, term := range v.Terms() {
+				delete(c.terms[term], m)
+				if len(c.terms[term]) == 0 {
+					delete(c.terms, term)
+				}
+			}
+		}
+	}
+	clear(c.members[len(members):])
+	c.members = members
+}
+
+// candidates 标题与文章有共同词的文章，按加入顺序排列
+func (c *Clusterer) candidates(m *member) []*member {
+	seen := make(map[*member]struct{})
+	list := make([]*member, 0)
+	for _, v := range []utils.Vector{m.vector, m.vectorCN} {
+		for _, term := range v.Terms() {
+			for other := range c.terms[term] {
+				if _, ok := seen[other]; !ok {
+					seen[other] = struct{}{}
+					list = append(list, other)
+				}
+			}
+		}
+	}
+	slices.SortFunc(list, func(a, b *member) int {
+		return a.seq - b.seq
+	})
+
+	return list
+}
+
+// similarity 标题相似度和对应阈值，中英文标题分别比较取最大值。
+// 不同语言的文章其中一方为机器翻译，使用较低的跨语言阈值
+func (c *Clusterer) similarity(m, article *member) (float64, float64) {
+	similarity := m.vector.Cosine(article.vector)
+	if m.titleCN != "" && article.titleCN != "" {
+		similarity = max(similarity, m.vectorCN.Cosine(article.vectorCN))
+	}
+
+	if m.lang != "" && article.lang != "" && m.lang != article.lang {
+		return similarity, c.cross
+	}
+
+	return similarity, c.threshold
+}
+
+// Assign 为文章分配事件，没有相似文章时创建新事件
+func (c *Clusterer) Assign(article *models.Article) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	article.Token = article.GenToken()
+	c.prune()
+
+	// 已经聚合
+	if m, ok := c.tokens[article.Token]; ok {
+		article.Story = m.story
+		return nil
+	}
+
+	var (
+		current    = newMember(article, "")
+		best       *member
+		similarity float64
+		margin     float64
+	)
+	for _, m := range c.candidates(current) {
+		// 选择超出阈值最多的文章
+		if s, threshold := c.similarity(m, current); s >= threshold && (best == nil || s-threshold > margin) {
+			best, similarity, margin = m, s, s-threshold
+		}
+	}
+
+	article.Story = article.Token
+	if best != nil {
+		article.Story = best.story
+		logger.Infof("Article %s joined story %s with %s (%.2f)", article.Link, best.story, best.from, similarity)
+	}
+	current.story = article.Story
+	c.add(current)
+
+	return c.save(article.Story)
+}
+
+// save 根据事件中的文章更新事件信息
+func (c *Clusterer) save(token string) error {
+	var (
+		story = &models.Story{Token: token}
+		rep   *member
+	)
+	for _, m := range c.members {
+		if m.story != token {
+			continue
+		}
+
+		if !slices.Contains(story.Origins, m.from) {
+			story.Origins = append(story.Origins, m.from)
+		}
+		story.Articles++
+		if story.FirstTime.IsZero() || m.time.Before(story.FirstTime) {
+			story.FirstTime = m.time
+		}
+		if m.time.After(story.LastTime) {
+			story.LastTime = m.time
+		}
+		if rep == nil || m.rank > rep.rank || (m.rank == rep.rank && m.time.Before(rep.time)) {
+			rep = m
+		}
+	}
+	// 只有一个来源报道的事件不保存
+	if rep == nil || len(story.Origins) < 2 {
+		return nil
+	}
+
+	story.ArticleToken = rep.token
+	story.Title = rep.title
+	story.TitleCN = rep.titleCN
+	story.Sources = len(story.Origins)
+	story.CreateTime = time.Now()
+	story.UpdateTime = time.Now()
+
+	return c.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"article_token", "title", "title_ch", "origins", "sources", "articles", "first_time", "last_time", "update_time",
+		}),
+	}).Create(story).Error
+}
@@ -0,0 +1,252 @@
+package summary
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"net/http"
+	"news/src/config"
+	"news/src/models"
+	"news/src/translator"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// minContent 正文少于该字数时不生成简介，避免大模型根据标题编造内容
+	minContent = 80
+
+	// maxContent 发送给大模型的正文最大字数
+	maxContent = 4000
+
+	// maxPage 文章详情页最大读取字节数
+	maxPage = 5 << 20
+)
+
+var (
+	ErrNoContent = errors.New("article content not found")
+	ErrFailed    = errors.New("summary failed recently")
+)
+
+// contentSelectors 各来源网站文章页正文选择器
+var contentSelectors = map[string][]string{
+	"jinse":      {"div.js-article", "div.article-main"},
+	"bitpie":     {"div.entry-content"},
+	"theblock":   {"div.articleContent", "article"},
+	"thedefiant": {"article"},
+}
+
+// defaultSelectors 未配置选择器的来源网站使用的正文选择器
+var defaultSelectors = []string{"article", "main"}
+
+// reply 大模型回复
+type reply struct {
+	EN string `json:"en"`
+	ZH string `json:"zh"`
+}
+
+// Summarizer 来源网站没有提供简介时，根据文章详情页正文由大模型生成中英文简介，生成结果按文章保存
+type Summarizer struct {
+	completer translator.Completer
+	prompt    string
+	db        *gorm.DB
+	length    int
+	lengthCN  int
+	retry     time.Duration
+	version   string
+}
+
+func NewSummarizer() (*Summarizer, error) {
+	c := config.Cfg.Summary
+	s := &Summarizer{completer: translator.NewCompleter(), db: models.DB, length: c.Length, lengthCN: c.LengthCN}
+	if s.length <= 0 {
+		s.length = 300
+	}
+	if s.lengthCN <= 0 {
+		s.lengthCN = 120
+	}
+	if s.retry = time.Duration(c.Retry) * time.Hour; s.retry <= 0 {
+		s.retry = 24 * time.Hour
+	}
+
+	s.prompt = fmt.Sprintf("你是加密货币新闻编辑。根据文章标题和正文写一段简介，只概括正文中的事实，不要添加正文中没有的信息。"+
+		"英文简介1到2句，不超过%d个字符；中文简介1到2句，不超过%d个字。"+
+		`只输出JSON：{"en":"英文简介","zh":"中文简介"}`, s.length, s.lengthCN)
+	// 提示词或模型变化时重新生成
+	names := strings.Join(translator.CompleterModels(s.completer), ",")
+	hash := sha256.Sum256([]byte(s.prompt + "|" + names))
+	s.version = hex.EncodeToString(hash[:])[:16]
+
+	return s, nil
+}
+
+// Summarize 生成简介并标记为机器生成，已生成过的文章直接使用保存的结果。
+// 正文获取不到或回复无法解析时记录失败，重试时间前直接返回失败
+func (s *Summarizer) Summarize(ctx context.Context, article *models.Article) error {
+	token := article.GenToken()
+	summary := &models.Summary{}
+	err := s.db.Where("token = ? AND version = ?", token, s.version).First(summary).Error
+	if err == nil {
+		if summary.Failure == "" {
+			apply(article, summary)
+			return nil
+		}
+		if summary.RetryTime != nil && time.Now().Before(*summary.RetryTime) {
+			return fmt.Errorf("%w: %s", ErrFailed, summary.Failure)
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	content, err := fetchContent(ctx, article)
+	if errors.Is(err, ErrNoContent) {
+		return s.fail(token, err)
+	}
+	if err != nil {
+		return err
+	}
+	text, err := s.completer.Complete(ctx, s.prompt, fmt.Sprintf("标题：%s\n正文：%s", article.Title, content))
+	if err != nil {
+		return err
+	}
+
+	r, err := s.parse(text)
+	if err != nil {
+		return s.fail(token, err)
+	}
+
+	summary = &models.Summary{
+		Token:      token,
+		Version:    s.version,
+		Abstract:   r.EN,
+		AbstractCN: r.ZH,
+		CreateTime: time.Now(),
+	}
+	if err = s.save(summary); err != nil {
+		return err
+	}
+	apply(article, summary)
+
+	return nil
+}
+
+// parse 解析大模型回复，简介为空时视为失败
+func (s *Summarizer) parse(text string) (*reply, error) {
+	r := &reply{}
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid summary reply: %s", text)
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), r); err != nil {
+		return nil, err
+	}
+	if r.EN, r.ZH = clip(r.EN, s.length), clip(r.ZH, s.lengthCN); r.EN == "" || r.ZH == "" {
+		return nil, fmt.Errorf("incomplete summary reply: %s", text)
+	}
+
+	return r, nil
+}
+
+// fail 记录生成失败，重试时间后重新生成
+func (s *Summarizer) fail(token string, cause error) error {
+	retry := time.Now().Add(s.retry)
+	return errors.Join(cause, s.save(&models.Summary{
+		Token:      token,
+		Version:    s.version,
+		Failure:    cause.Error(),
+		RetryTime:  &retry,
+		CreateTime: time.Now(),
+	}))
+}
+
+// save 保存生成结果，覆盖同一版本之前的结果
+func (s *Summarizer) save(summary *models.Summary) error {
+	return s.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"abstract", "abstract_ch", "failure", "retry_time", "create_time"}),
+	}).Create(summary).Error
+}
+
+func apply(article *models.Article, summary *models.Summary) {
+	article.Abstract = summary.Abstract
+	article.AbstractCN = summary.AbstractCN
+	article.AbstractGenerated = true
+}
+
+// fetchContent 获取文章详情页正文，按段落拼接并限制长度
+func fetchContent(ctx context.Context, article *models.Article) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, article.Link, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", config.Cfg.Scrapy.UA)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+	resp, err := http.DefaultClient.Do(req) // 超时由 ctx 控制
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(io.LimitReader(resp.Body, maxPage))
+	if err != nil {
+		return "", err
+	}
+
+	for _, sel := range slices.Concat(contentSelectors[article.From], defaultSelectors) {
+		container := doc.Find(sel).First()
+		if container.Length() == 0 {
+			continue
+		}
+
+		paragraphs := make([]string, 0)
+		container.Find("p").Each(func(_ int, p *goquery.Selection) {
+			if text := strings.TrimSpace(p.Text()); text != "" {
+				paragraphs = append(paragraphs, text)
+			}
+		})
+		content := strings.Join(paragraphs, "\n")
+		if content == "" {
+			content = strings.TrimSpace(container.Text())
+		}
+		if utf8.RuneCountInString(content) >= minContent {
+			return clip(content, maxContent), nil
+		}
+	}
+
+	return "", ErrNoContent
+}
+
+// clip 截断到长度限制内最后一个完整句子，没有完整句子时直接截断
+func clip(text string, limit int) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	for i := limit - 1; i > 0; i-- {
+		switch runes[i] {
+		case '。', '！', '？':
+			return string(runes[:i+1])
+		case '.', '!', '?': // 英文句号后需要空白，避免在小数点处截断
+			if unicode.IsSpace(runes[i+1]) {
+				return string(runes[:i+1])
+			}
+		}
+	}
+
+	return strings.TrimSpace(string(runes[:limit])) + "…"
+}
@@ -0,0 +1,186 @@
+package topic
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"news/src/config"
+	"news/src/logger"
+	"news/src/models"
+	"news/src/translator"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+//go:embed topics.json
+var defaultTopics []byte
+
+// Topic 文章主题
+type Topic struct {
+	Slug     string   `json:"slug"`
+	NameEN   string   `json:"name_en"`
+	NameZH   string   `json:"name_zh"`
+	Keywords []string `json:"keywords"`
+
+	patterns []*regexp.Regexp
+	keywords []string
+}
+
+func (t *Topic) GetNameByLang(lang string) string {
+	if models.IsChinese(lang) && t.NameZH != "" {
+		return models.ConvertChinese(t.NameZH, lang)
+	}
+
+	return t.NameEN
+}
+
+// compile 生成匹配规则，包含大写字母的关键词区分大小写，中文关键词按子串匹配
+func (t *Topic) compile() {
+	var sensitive, insensitive []string
+	for _, keyword := range t.Keywords {
+		switch {
+		case isHan(keyword):
+			t.keywords = append(t.keywords, keyword)
+		case strings.ToLower(keyword) != keyword:
+			sensitive = append(sensitive, regexp.QuoteMeta(keyword))
+		default:
+			insensitive = append(insensitive, regexp.QuoteMeta(keyword))
+		}
+	}
+
+	if len(sensitive) > 0 {
+		t.patterns = append(t.patterns, regexp.MustCompile(`\b(?:`+strings.Join(sensitive, "|")+`)\b`))
+	}
+	if len(insensitive) > 0 {
+		t.patterns = append(t.patterns, regexp.MustCompile(`(?i)\b(?:`+strings.Join(insensitive, "|")+`)\b`))
+	}
+}
+
+// Match 文本中是否包含主题关键词
+func (t *Topic) Match(text string) bool {
+	for _, keyword := range t.keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	for _, pattern := range t.patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var topics []*Topic
+
+func init() {
+	if err := json.Unmarshal(defaultTopics, &topics); err != nil {
+		panic(err)
+	}
+	for _, t := range topics {
+		t.compile()
+	}
+}
+
+// Topics 所有主题
+func Topics() []*Topic {
+	return topics
+}
+
+// Classifier 文章主题分类
+type Classifier interface {
+	Classify(ctx context.Context, article *models.Article) []string
+}
+
+// NewClassifier 根据配置创建分类器，llm 模式使用大模型分类，失败时使用关键词分类
+func NewClassifier() Classifier {
+	keyword := &KeywordClassifier{}
+	if config.Cfg.Topic.Mode != "llm" {
+		return keyword
+	}
+
+	return NewLLMClassifier(translator.NewCompleter(), keyword)
+}
+
+// KeywordClassifier 基于关键词规则的分类器
+type KeywordClassifier struct{}
+
+func (c *KeywordClassifier) Classify(_ context.Context, article *models.Article) []string {
+	text := strings.Join([]string{article.Title, article.TitleCN, article.Abstract, article.AbstractCN}, "\n")
+	slugs := make([]string, 0)
+	for _, t := range topics {
+		if t.Match(text) {
+			slugs = append(slugs, t.Slug)
+		}
+	}
+
+	return slugs
+}
+
+// LLMClassifier 基于大模型的分类器，按翻译服务配置的顺序使用支持对话的服务
+type LLMClassifier struct {
+	completer translator.Completer
+	prompt    string
+	fallback  Classifier
+}
+
+func NewLLMClassifier(completer translator.Completer, fallback Classifier) *LLMClassifier {
+	list := make([]string, 0, len(topics))
+	for _, t := range topics {
+		list = append(list, fmt.Sprintf("%s (%s)", t.Slug, t.NameEN))
+	}
+	prompt := fmt.Sprintf("You are a crypto news editor. Classify the news article into zero or more of these topics: %s. "+
+		`Reply only with JSON: {"topics":["slug"]}, use an empty list if no topic matches.`,
+		strings.Join(list, ", "))
+
+	return &LLMClassifier{completer: completer, prompt: prompt, fallback: fallback}
+}
+
+func (c *LLMClassifier) Classify(ctx context.Context, article *models.Article) []string {
+	content := article.Title
+	if article.Abstract != "" {
+		content += "\n" + article.Abstract
+	}
+
+	reply, err := c.completer.Complete(ctx, c.prompt, content)
+	if err != nil {
+		logger.Warnf("Failed to classify article %s: %s", article.Link, err)
+		return c.fallback.Classify(ctx, article)
+	}
+
+	result := struct {
+		Topics []string `json:"topics"`
+	}{}
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start || json.Unmarshal([]byte(reply[start:end+1]), &result) != nil {
+		logger.Warnf("Invalid topic reply for %s: %s", article.Link, reply)
+		return c.fallback.Classify(ctx, article)
+	}
+
+	// 只保留已定义的主题
+	slugs := make([]string, 0)
+	for _, s := range result.Topics {
+		s = strings.ToLower(strings.TrimSpace(s))
+		for _, t := range topics {
+			if t.Slug == s && !slices.Contains(slugs, s) {
+				slugs = append(slugs, s)
+			}
+		}
+	}
+
+	return slugs
+}
+
+func isHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+
+	return false
+}
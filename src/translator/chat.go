@@ -0,0 +1,180 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"news/src/config"
+	"news/src/logger"
+	"strconv"
+	"strings"
+)
+
+// retries 批量翻译结果不完整时重试次数，仍失败的条目逐条翻译
+const retries = 1
+
+// langNames 目标语言名称，用于大模型提示词
+var langNames = map[string]string{
+	"en":      "英文",
+	"zh":      "中文",
+	"zh-Hant": "繁体中文",
+	"ja":      "日文",
+	"ko":      "韩文",
+	"es":      "西班牙文",
+	"fr":      "法文",
+	"de":      "德文",
+	"ru":      "俄文",
+	"pt":      "葡萄牙文",
+	"vi":      "越南文",
+	"th":      "泰文",
+	"tr":      "土耳其文",
+}
+
+// protocol 批量翻译协议说明
+const protocol = `输入为JSON：{"target":"目标语言","items":[{"id":"编号","text":"原文"}]}。
+只输出JSON：{"items":[{"id":"编号","text":"译文"}]}。每个id必须且只能出现一次，不要合并、拆分或省略条目，译文中不要添加任何说明。`
+
+// strict 译文未通过校验时追加的提示词
+const strict = `上次的译文未通过校验。严格要求：译文必须使用目标语言，不得原样返回原文，不得添加解释、注释、前缀或多余的换行，长度与原文相当。`
+
+// chatItem 批量翻译条目
+type chatItem struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// chatPayload 批量翻译请求和回复
+type chatPayload struct {
+	Target string     `json:"target,omitempty"`
+	Items  []chatItem `json:"items"`
+}
+
+// message 对话消息
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatFunc func(ctx context.Context, messages []message) (string, error)
+
+// prompt 大模型翻译提示词，包括文本中出现的术语，重试时使用更严格的提示词
+func prompt(target string, retry bool, texts ...string) string {
+	name, ok := langNames[target]
+	if !ok {
+		name = target
+	}
+
+	p := fmt.Sprintf("%s\n请翻译为%s。\n%s\n%s", config.Cfg.Kimi.Prompt, name, DefaultGlossary().Prompt(target, texts...), protocol)
+	if retry {
+		p += "\n" + strict
+	}
+
+	return p
+}
+
+// chatTranslate 通过大模型对话批量翻译，按编号对应原文和译文。
+// 回复缺少、重复条目或译文未通过校验时使用更严格的提示词重试，仍失败的条目逐条翻译
+func chatTranslate(ctx context.Context, chat chatFunc, target string, texts []string) ([]string, error) {
+	results := make([]string, len(texts))
+	pending := make([]int, 0, len(texts))
+	for i := range texts {
+		pending = append(pending, i)
+	}
+
+	var err error
+	for attempt := 0; attempt <= retries && len(pending) > 0; attempt++ {
+		if pending, err = chatBatch(ctx, chat, target, texts, pending, results, attempt > 0); err != nil {
+			logger.Warnf("Batch translation failed: %s", err)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	if len(pending) == len(texts) && err != nil {
+		return nil, err
+	}
+	for _, i := range pending {
+		if _, err = chatBatch(ctx, chat, target, texts, []int{i}, results, true); err != nil {
+			logger.Warnf("Translation of item %d failed: %s", i, err)
+		}
+	}
+
+	return results, nil
+}
+
+// chatBatch 翻译指定条目，写入校验通过的译文，返回未翻译成功的条目
+func chatBatch(ctx context.Context, chat chatFunc, target string, texts []string, indexes []int, results []string, retry bool) ([]int, error) {
+	payload := chatPayload{Target: target, Items: make([]chatItem, 0, len(indexes))}
+	batch := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		payload.Items = append(payload.Items, chatItem{ID: strconv.Itoa(i), Text: texts[i]})
+		batch = append(batch, texts[i])
+	}
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return indexes, err
+	}
+
+	reply, err := chat(ctx, []message{
+		{Role: "system", Content: prompt(target, retry, batch...)},
+		{Role: "user", Content: string(content)},
+	})
+	if err != nil {
+		return indexes, err
+	}
+
+	translations, err := parseReply(reply)
+	if err != nil {
+		return indexes, err
+	}
+
+	missing := make([]int, 0)
+	for _, i := range indexes {
+		text, ok := translations[strconv.Itoa(i)]
+		if !ok || text == "" {
+			missing = append(missing, i)
+			continue
+		}
+		if e := Validate(target, texts[i], text); e != nil {
+			logger.Warnf("Invalid translation of %s: %s", texts[i], e)
+			missing = append(missing, i)
+			continue
+		}
+		results[i] = text
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf("%w: %d of %d items", ErrMismatch, len(missing), len(indexes))
+	}
+
+	return missing, err
+}
+
+// parseReply 解析回复中的JSON，重复出现的编号视为无效
+func parseReply(reply string) (map[string]string, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("reply is not json")
+	}
+
+	payload := chatPayload{}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &payload); err != nil {
+		return nil, err
+	}
+
+	translations := make(map[string]string, len(payload.Items))
+	duplicates := make(map[string]struct{})
+	for _, item := range payload.Items {
+		if _, ok := translations[item.ID]; ok {
+			duplicates[item.ID] = struct{}{}
+		}
+		translations[item.ID] = strings.TrimSpace(item.Text)
+	}
+	for id := range duplicates {
+		delete(translations, id)
+	}
+
+	return translations, nil
+}
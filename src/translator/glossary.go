@@ -0,0 +1,259 @@
+package translator
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"news/src/config"
+	"news/src/logger"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+//go:embed glossary.json
+var defaultGlossary []byte
+
+// protectedRegexp 保持原文的网址和代币符号
+var protectedRegexp = regexp.MustCompile(`https?://\S+|\$[A-Za-z]{2,10}\b`)
+
+// Term 术语，en 和 zh 为对应的英文和中文译法
+type Term struct {
+	EN string `json:"en"`
+	ZH string `json:"zh"`
+}
+
+// get 指定语言的译法
+func (t Term) get(lang string) string {
+	if lang == "zh" {
+		return t.ZH
+	}
+
+	return t.EN
+}
+
+// Glossary 术语表，包括术语译法和保持原文不翻译的词，编辑修改术语表文件后自动重新加载
+type Glossary struct {
+	path     string
+	terms    []Term
+	keep     []string
+	patterns map[string]*regexp.Regexp
+	version  string
+	modTime  time.Time
+	checked  time.Time
+	lock     sync.RWMutex
+	loading  sync.Mutex
+}
+
+var (
+	glossary     *Glossary
+	glossaryOnce sync.Once
+)
+
+// DefaultGlossary 配置的术语表文件，未配置时使用内置术语表
+func DefaultGlossary() *Glossary {
+	glossaryOnce.Do(func() {
+		glossary = &Glossary{path: config.Cfg.Translate.Glossary}
+		if err := glossary.load(defaultGlossary); err != nil {
+			panic(err)
+		}
+		glossary.reload()
+	})
+
+	return glossary
+}
+
+func (g *Glossary) load(data []byte) error {
+	list := struct {
+		Terms []Term   `json:"terms"`
+		Keep  []string `json:"keep"`
+	}{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	patterns := make(map[string]*regexp.Regexp)
+	for _, t := range list.Terms {
+		if p := compileTerm(t.EN); p != nil {
+			patterns[t.EN] = p
+		}
+	}
+	for _, k := range list.Keep {
+		if p := compileTerm(k); p != nil {
+			patterns[k] = p
+		}
+	}
+
+	hash := sha256.Sum256(data)
+	g.lock.Lock()
+	g.terms = list.Terms
+	g.keep = list.Keep
+	g.patterns = patterns
+	g.version = hex.EncodeToString(hash[:])[:8]
+	g.lock.Unlock()
+
+	return nil
+}
+
+// reload 术语表文件修改后重新加载，每分钟最多检查一次
+func (g *Glossary) reload() {
+	g.loading.Lock()
+	defer g.loading.Unlock()
+
+	if g.path == "" || time.Since(g.checked) < time.Minute {
+		return
+	}
+	g.checked = time.Now()
+
+	info, err := os.Stat(g.path)
+	if err != nil || !info.ModTime().After(g.modTime) {
+		return
+	}
+	data, err := os.ReadFile(g.path)
+	if err == nil {
+		err = g.load(data)
+	}
+	if err != nil {
+		logger.Errorf("Failed to load glossary %s: %s", g.path, err)
+		return
+	}
+
+	g.modTime = info.ModTime()
+	logger.Infof("Loaded glossary %s, version %s", g.path, g.version)
+}
+
+// Version 术语表版本，术语表修改后翻译记忆失效
+func (g *Glossary) Version() string {
+	g.reload()
+
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return g.version
+}
+
+// compileTerm 英文术语按单词匹配并允许复数形式，包含大写字母的术语区分大小写，如 SEC 不匹配 security；
+// 中文术语按子串匹配，返回 nil
+func compileTerm(term string) *regexp.Regexp {
+	if term == "" || strings.IndexFunc(term, func(r rune) bool { return unicode.Is(unicode.Han, r) }) >= 0 {
+		return nil
+	}
+
+	expr := `\b` + regexp.QuoteMeta(term) + `(?:s|es)?\b`
+	if strings.ToLower(term) == term {
+		expr = `(?i)` + expr
+	}
+
+	return regexp.MustCompile(expr)
+}
+
+// contains 文本中是否包含术语
+func (g *Glossary) contains(text, term string) bool {
+	if term == "" {
+		return false
+	}
+	if p, ok := g.patterns[term]; ok {
+		return p.MatchString(text)
+	}
+
+	return strings.Contains(text, term)
+}
+
+// Prompt 文本中出现的术语和保持原文的词，用于翻译提示词
+func (g *Glossary) Prompt(target string, texts ...string) string {
+	g.reload()
+
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	source := sourceLang(target)
+	text := strings.Join(texts, "\n")
+	terms := make([]string, 0)
+	for _, t := range g.termsFor(target) {
+		if g.contains(text, t.get(source)) {
+			terms = append(terms, fmt.Sprintf("%s => %s", t.get(source), t.get(target)))
+		}
+	}
+	keep := make([]string, 0)
+	for _, k := range g.keep {
+		if g.contains(text, k) {
+			keep = append(keep, k)
+		}
+	}
+
+	prompt := "网址和以$开头的代币符号保持原文不翻译。"
+	if len(terms) > 0 {
+		prompt += "\n术语必须使用以下译法：" + strings.Join(terms, "；")
+	}
+	if len(keep) > 0 {
+		prompt += "\n以下词语保持原文不翻译：" + strings.Join(keep, "，")
+	}
+
+	return prompt
+}
+
+// Check 检查译文是否符合术语表，返回不符合的术语
+func (g *Glossary) Check(target, text, translation string) []string {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	source := sourceLang(target)
+	violations := make([]string, 0)
+	for _, t := range g.termsFor(target) {
+		if g.contains(text, t.get(source)) && !g.contains(translation, t.get(target)) {
+			violations = append(violations, t.get(source))
+		}
+	}
+	for _, k := range g.keep {
+		if g.contains(text, k) && !g.contains(translation, k) {
+			violations = append(violations, k)
+		}
+	}
+	for _, p := range protectedRegexp.FindAllString(text, -1) {
+		if !strings.Contains(translation, p) {
+			violations = append(violations, p)
+		}
+	}
+
+	return violations
+}
+
+// Strip 去除保持原文的词语、网址和代币符号，用于校验译文文字
+func (g *Glossary) Strip(text string) string {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	text = protectedRegexp.ReplaceAllString(text, " ")
+	for _, k := range g.keep {
+		if p, ok := g.patterns[k]; ok {
+			text = p.ReplaceAllString(text, " ")
+		} else {
+			text = strings.ReplaceAll(text, k, " ")
+		}
+	}
+
+	return text
+}
+
+// termsFor 目标语言的术语，术语表只有英文和中文译法
+func (g *Glossary) termsFor(target string) []Term {
+	if target != "en" && target != "zh" {
+		return nil
+	}
+
+	return g.terms
+}
+
+// sourceLang 翻译原文语言，翻译为英文时原文为中文，其他语言均由英文翻译
+func sourceLang(target string) string {
+	if target == "en" {
+		return "zh"
+	}
+
+	return "en"
+}
@@ -0,0 +1,91 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"github.com/northes/go-moonshot"
+	"news/src/config"
+	"sync"
+)
+
+const defaultKimiModel = moonshot.ModelMoonshotV18K
+
+// KimiTranslator 基于Kimi的翻译
+type KimiTranslator struct {
+	name   string
+	client *moonshot.Client
+	model  moonshot.ChatCompletionsModelID
+	lock   sync.Mutex
+}
+
+func NewKimiTranslator(name, key, model string) (*KimiTranslator, error) {
+	if key == "" {
+		key = config.Cfg.Kimi.Key
+	}
+	client, err := moonshot.NewClient(key)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &KimiTranslator{
+		name:   name,
+		client: client,
+		model:  moonshot.ChatCompletionsModelID(model),
+	}
+	if t.model == "" {
+		t.model = defaultKimiModel
+	}
+
+	return t, nil
+}
+
+func (t *KimiTranslator) Name() string {
+	return t.name
+}
+
+func (t *KimiTranslator) Model() string {
+	return string(t.model)
+}
+
+func (t *KimiTranslator) Translate(ctx context.Context, target string, texts ...string) ([]string, error) {
+	return chatTranslate(ctx, t.chat, target, texts)
+}
+
+// Complete 单轮对话，回复为JSON
+func (t *KimiTranslator) Complete(ctx context.Context, system, user string) (string, error) {
+	return t.chat(ctx, []message{
+		{Role: "system", Content: system},
+		{Role: "user", Content: user},
+	})
+}
+
+func (t *KimiTranslator) chat(ctx context.Context, messages []message) (string, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	list := make([]*moonshot.ChatCompletionsMessage, 0, len(messages))
+	for _, m := range messages {
+		list = append(list, &moonshot.ChatCompletionsMessage{
+			Role:    moonshot.ChatCompletionsMessageRole(m.Role),
+			Content: m.Content,
+		})
+	}
+
+	resp, err := t.client.Chat().Completions(ctx, &moonshot.ChatCompletionsRequest{
+		Model:       t.model,
+		Temperature: 0.0,
+		Stream:      false,
+		Messages:    list,
+		ResponseFormat: &moonshot.ChatCompletionsRequestResponseFormat{
+			Type: moonshot.ChatCompletionsResponseFormatJSONObject,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("empty response")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
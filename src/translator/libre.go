@@ -0,0 +1,106 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// libreModel LibreTranslate 没有模型，翻译记忆中使用服务类型区分
+const libreModel = "libretranslate"
+
+// libreCodes LibreTranslate 语言代码，1.6 以前的版本简体中文为 zh，繁体中文为 zt
+var libreCodes = map[string]string{
+	"zh-Hans": "zh",
+	"zh-Hant": "zt",
+	"zh-TW":   "zt",
+	"zh-HK":   "zt",
+}
+
+// LibreTranslator 基于自建LibreTranslate服务的翻译
+type LibreTranslator struct {
+	name   string
+	url    string
+	key    string
+	codes  map[string]string
+	client *http.Client
+}
+
+// NewLibreTranslator 创建LibreTranslate翻译，codes 为 语言:服务语言代码 格式的映射，覆盖默认映射
+func NewLibreTranslator(name, url, key string, codes []string) *LibreTranslator {
+	t := &LibreTranslator{
+		name:   name,
+		url:    strings.TrimSuffix(url, "/"),
+		key:    key,
+		codes:  make(map[string]string, len(libreCodes)+len(codes)),
+		client: &http.Client{},
+	}
+	for lang, code := range libreCodes {
+		t.codes[lang] = code
+	}
+	for _, c := range codes {
+		if lang, code, ok := strings.Cut(c, ":"); ok {
+			t.codes[strings.TrimSpace(lang)] = strings.TrimSpace(code)
+		}
+	}
+
+	return t
+}
+
+// code 目标语言对应的服务语言代码
+func (t *LibreTranslator) code(target string) string {
+	if code, ok := t.codes[target]; ok {
+		return code
+	}
+
+	return target
+}
+
+func (t *LibreTranslator) Name() string {
+	return t.name
+}
+
+func (t *LibreTranslator) Model() string {
+	return libreModel
+}
+
+func (t *LibreTranslator) Translate(ctx context.Context, target string, texts ...string) ([]string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"q":       texts,
+		"source":  "auto",
+		"target":  t.code(target),
+		"format":  "text",
+		"api_key": t.key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	result := struct {
+		TranslatedText []string `json:"translatedText"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.TranslatedText, nil
+}
@@ -0,0 +1,143 @@
+package translator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"news/src/config"
+	"news/src/logger"
+	"news/src/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Memory 翻译记忆，已翻译过的文本直接使用保存的译文。
+// 记忆按提示词、协议、术语表和模型区分版本，修改后重新翻译；翻译服务的顺序变化不影响已保存的译文
+type Memory struct {
+	next Translator
+	db   *gorm.DB
+}
+
+func NewMemory(next Translator) *Memory {
+	return &Memory{
+		next: next,
+		db:   models.DB,
+	}
+}
+
+// version 指定模型的翻译记忆版本
+func (m *Memory) version(model string) string {
+	data := strings.Join([]string{config.Cfg.Translate.Version, config.Cfg.Kimi.Prompt, protocol, DefaultGlossary().Version(), model}, "|")
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+// versions 配置的翻译服务对应的记忆版本，按配置顺序排列。按配置计算，翻译服务初始化失败时已保存的译文仍然有效
+func (m *Memory) versions() []string {
+	versions := make([]string, 0, len(config.Cfg.Translate.Backend))
+	for _, name := range config.Cfg.Translate.Backend {
+		if v := m.version(backendModel(name)); !slices.Contains(versions, v) {
+			versions = append(versions, v)
+		}
+	}
+
+	return versions
+}
+
+func (m *Memory) Name() string {
+	return m.next.Name()
+}
+
+func (m *Memory) Translate(ctx context.Context, target string, texts ...string) ([]string, error) {
+	results, err := m.results(ctx, target, texts...)
+	translations := make([]string, len(results))
+	for i, r := range results {
+		translations[i] = r.Text
+	}
+
+	return translations, err
+}
+
+func (m *Memory) results(ctx context.Context, target string, texts ...string) ([]Result, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]string, len(texts))
+	for i, text := range texts {
+		hash := sha256.Sum256([]byte(text))
+		hashes[i] = hex.EncodeToString(hash[:])
+	}
+
+	// 查询翻译记忆，多个版本都有译文时优先使用配置顺序靠前的翻译服务
+	versions := m.versions()
+	rows := make([]*models.TranslationMemory, 0, len(texts))
+	if len(versions) > 0 {
+		err := m.db.Where("hash IN ? AND target = ? AND version IN ?", hashes, target, versions).Find(&rows).Error
+		if err != nil {
+			logger.Errorf("Failed to query translation memory: %s", err)
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b *models.TranslationMemory) int {
+		return slices.Index(versions, a.Version) - slices.Index(versions, b.Version)
+	})
+	memory := make(map[string]*models.TranslationMemory, len(rows))
+	for _, row := range rows {
+		if _, ok := memory[row.Hash]; !ok {
+			memory[row.Hash] = row
+		}
+	}
+
+	results := make([]Result, len(texts))
+	missing := make([]int, 0)
+	for i, hash := range hashes {
+		if row, ok := memory[hash]; ok && Validate(target, texts[i], row.Text) == nil {
+			results[i] = Result{Text: row.Text, Translator: row.Translator, Violations: DefaultGlossary().Check(target, texts[i], row.Text)}
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	batch := make([]string, 0, len(missing))
+	for _, i := range missing {
+		batch = append(batch, texts[i])
+	}
+	translations, err := Results(ctx, m.next, target, batch...)
+	if len(translations) != len(batch) {
+		return results, err
+	}
+
+	// 保存翻译成功的条目，记录实际翻译的服务
+	records := make([]*models.TranslationMemory, 0, len(missing))
+	for j, i := range missing {
+		if translations[j].Text == "" {
+			continue
+		}
+		results[i] = translations[j]
+		records = append(records, &models.TranslationMemory{
+			Hash:       hashes[i],
+			Target:     target,
+			Version:    m.version(translations[j].Model),
+			Source:     texts[i],
+			Text:       translations[j].Text,
+			Translator: translations[j].Translator,
+			CreateTime: time.Now(),
+			UpdateTime: time.Now(),
+		})
+	}
+	if len(records) > 0 {
+		if e := m.db.Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"text", "translator", "update_time"}),
+		}).Create(&records).Error; e != nil {
+			logger.Errorf("Failed to save translation memory: %s", e)
+		}
+	}
+
+	return results, err
+}
@@ -0,0 +1,101 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenAITranslator 基于OpenAI兼容对话接口的翻译
+type OpenAITranslator struct {
+	name   string
+	url    string
+	key    string
+	model  string
+	client *http.Client
+}
+
+func NewOpenAITranslator(name, url, key, model string) (*OpenAITranslator, error) {
+	if url == "" || model == "" {
+		return nil, errors.New("url and model are required")
+	}
+
+	return &OpenAITranslator{
+		name:   name,
+		url:    strings.TrimSuffix(url, "/"),
+		key:    key,
+		model:  model,
+		client: &http.Client{},
+	}, nil
+}
+
+func (t *OpenAITranslator) Name() string {
+	return t.name
+}
+
+func (t *OpenAITranslator) Model() string {
+	return t.model
+}
+
+func (t *OpenAITranslator) Translate(ctx context.Context, target string, texts ...string) ([]string, error) {
+	return chatTranslate(ctx, t.chat, target, texts)
+}
+
+// Complete 单轮对话，回复为JSON
+func (t *OpenAITranslator) Complete(ctx context.Context, system, user string) (string, error) {
+	return t.chat(ctx, []message{
+		{Role: "system", Content: system},
+		{Role: "user", Content: user},
+	})
+}
+
+func (t *OpenAITranslator) chat(ctx context.Context, messages []message) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":       t.model,
+		"temperature": 0,
+		"messages":    messages,
+		"response_format": map[string]string{
+			"type": "json_object",
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.key != "" {
+		req.Header.Set("Authorization", "Bearer "+t.key)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	result := struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if len(result.Choices) == 0 {
+		return "", errors.New("empty response")
+	}
+
+	return result.Choices[0].Message.Content, nil
+}
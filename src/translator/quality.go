@@ -0,0 +1,108 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrScript     = errors.New("translation is not in target language")
+	ErrLength     = errors.New("translation length out of range")
+	ErrEcho       = errors.New("translation equals source")
+	ErrCommentary = errors.New("translation contains commentary")
+)
+
+// minRatioLength 原文少于该字数时不校验长度比例
+const minRatioLength = 8
+
+// lengthRatios 译文与原文字数比例范围，按目标语言区分
+var lengthRatios = map[string][2]float64{
+	"zh": {0.12, 1.5},
+	"en": {0.8, 8},
+}
+
+// acronymRegexp 大写缩写和代币符号，通常保持原文
+var acronymRegexp = regexp.MustCompile(`\b[A-Z][A-Z0-9]{1,9}\b`)
+
+// commentaryRegexp 译文中常见的说明、前缀和注释，中文前缀必须带标点，避免误判以注册、好的、翻译等词开头的译文
+var commentaryRegexp = regexp.MustCompile(`(?i)^\s*(translation|translated text|here is|here's|sure|note)\b|` +
+	`(?i)\b(translation|translated text|note)\s*[:：]|` +
+	`^\s*(译文|翻译|以下是(?:[^:：，,\n]{0,6}(?:译文|翻译)[^:：，,\n]{0,6})?|好的|注)\s*[:：，,]|[（(]\s*(注|译注|备注|note)\s*[:：]`)
+
+// Validate 校验译文：目标语言文字、长度比例、原样返回原文和附加说明
+func Validate(target, text, translation string) error {
+	text = strings.TrimSpace(text)
+	translation = strings.TrimSpace(translation)
+	if translation == "" {
+		return ErrIncomplete
+	}
+
+	// 附加说明，或者译文比原文多出段落
+	if commentaryRegexp.MatchString(translation) && !commentaryRegexp.MatchString(text) {
+		return ErrCommentary
+	}
+	if strings.Count(translation, "\n") > strings.Count(text, "\n") {
+		return ErrCommentary
+	}
+
+	// 去除保持原文的词语和缩写后，原文中需要翻译的文字
+	source := acronymRegexp.ReplaceAllString(DefaultGlossary().Strip(text), " ")
+	stripped := acronymRegexp.ReplaceAllString(DefaultGlossary().Strip(translation), " ")
+	if letters, _ := countLetters(source); letters == 0 {
+		return nil
+	}
+
+	if normalize(text) == normalize(translation) {
+		return ErrEcho
+	}
+
+	// 目标语言文字比例，只校验中文和英文
+	base, _, _ := strings.Cut(target, "-")
+	if letters, han := countLetters(stripped); letters > 0 {
+		ratio := float64(han) / float64(letters)
+		if base == "zh" && ratio < 0.3 || base == "en" && ratio > 0.2 {
+			return fmt.Errorf("%w: han ratio %.2f", ErrScript, ratio)
+		}
+	}
+
+	// 长度比例
+	if bounds, ok := lengthRatios[base]; ok {
+		length := utf8.RuneCountInString(text)
+		if length >= minRatioLength {
+			ratio := float64(utf8.RuneCountInString(translation)) / float64(length)
+			if ratio < bounds[0] || ratio > bounds[1] {
+				return fmt.Errorf("%w: ratio %.2f", ErrLength, ratio)
+			}
+		}
+	}
+
+	return nil
+}
+
+// countLetters 统计文字数和其中的汉字数
+func countLetters(text string) (letters, han int) {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			letters++
+			han++
+		} else if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+
+	return letters, han
+}
+
+// normalize 忽略大小写、空白和标点，用于判断是否原样返回
+func normalize(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
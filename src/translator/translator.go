@@ -0,0 +1,271 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"news/src/config"
+	"news/src/logger"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoTranslator = errors.New("no translator available")
+	ErrNoCompleter  = errors.New("no completer available")
+	ErrMismatch     = errors.New("translation count mismatch")
+	ErrIncomplete   = errors.New("translation incomplete")
+)
+
+// Translator 翻译服务
+type Translator interface {
+	Name() string
+	// Translate 批量翻译为目标语言，返回结果与输入一一对应，翻译失败的条目为空字符串
+	Translate(ctx context.Context, target string, texts ...string) ([]string, error)
+}
+
+// Result 译文及实际翻译的服务和模型，Violations 为译文不符合术语表的术语
+type Result struct {
+	Text       string
+	Translator string
+	Model      string
+	Violations []string
+}
+
+// resulter 可以返回实际翻译服务的翻译
+type resulter interface {
+	results(ctx context.Context, target string, texts ...string) ([]Result, error)
+}
+
+// modeler 翻译服务使用的模型
+type modeler interface {
+	Model() string
+}
+
+// Results 翻译并返回每条译文实际使用的翻译服务，不支持时使用翻译服务名称
+func Results(ctx context.Context, t Translator, target string, texts ...string) ([]Result, error) {
+	if r, ok := t.(resulter); ok {
+		return r.results(ctx, target, texts...)
+	}
+
+	translations, err := t.Translate(ctx, target, texts...)
+	results := make([]Result, len(translations))
+	for i, text := range translations {
+		results[i] = Result{Text: text, Translator: t.Name(), Model: modelOf(t)}
+	}
+
+	return results, err
+}
+
+// modelOf 翻译服务使用的模型，不支持时使用名称
+func modelOf(t Translator) string {
+	if m, ok := t.(modeler); ok {
+		return m.Model()
+	}
+
+	return t.Name()
+}
+
+// backendModel 根据配置获取翻译服务使用的模型，与翻译服务的 Model 一致。LibreTranslate 没有模型，使用服务类型
+func backendModel(name string) string {
+	c, ok := config.Cfg.Translator[name]
+	if !ok {
+		return name
+	}
+
+	switch {
+	case c.Type == "libre":
+		return libreModel
+	case c.Type == "kimi" && c.Model == "":
+		return string(defaultKimiModel)
+	}
+
+	return c.Model
+}
+
+// Completer 大模型对话服务，用于简介生成和主题分类，回复为JSON
+type Completer interface {
+	Complete(ctx context.Context, system, user string) (string, error)
+}
+
+// CompleterModels 大模型对话服务依次使用的模型，用于区分生成结果的版本
+func CompleterModels(c Completer) []string {
+	switch c := c.(type) {
+	case *Chain:
+		models := make([]string, 0, len(c.translators))
+		for _, t := range c.translators {
+			if _, ok := t.(Completer); ok {
+				models = append(models, modelOf(t))
+			}
+		}
+		return models
+	case Translator:
+		return []string{modelOf(c)}
+	}
+
+	return nil
+}
+
+// New 根据配置按顺序创建翻译服务，前一个翻译服务失败时使用下一个，并使用翻译记忆
+func New() Translator {
+	return NewMemory(newChain())
+}
+
+// NewCompleter 根据配置按顺序使用支持大模型对话的翻译服务，前一个失败时使用下一个
+func NewCompleter() Completer {
+	return newChain()
+}
+
+func newChain() *Chain {
+	chain := &Chain{timeout: time.Duration(config.Cfg.Translate.Timeout) * time.Second}
+	if chain.timeout <= 0 {
+		chain.timeout = 60 * time.Second
+	}
+
+	for _, name := range config.Cfg.Translate.Backend {
+		t, err := newTranslator(name)
+		if err != nil {
+			logger.Errorf("Failed to initialize translator %s: %s", name, err)
+			continue
+		}
+		chain.translators = append(chain.translators, t)
+	}
+
+	return chain
+}
+
+func newTranslator(name string) (Translator, error) {
+	c, ok := config.Cfg.Translator[name]
+	if !ok {
+		return nil, fmt.Errorf("translator %s not configured", name)
+	}
+
+	switch c.Type {
+	case "kimi":
+		return NewKimiTranslator(name, c.Key, c.Model)
+	case "openai":
+		return NewOpenAITranslator(name, c.URL, c.Key, c.Model)
+	case "libre":
+		return NewLibreTranslator(name, c.URL, c.Key, c.Lang), nil
+	}
+
+	return nil, fmt.Errorf("unknown translator type: %s", c.Type)
+}
+
+// Chain 按顺序使用的翻译服务
+type Chain struct {
+	translators []Translator
+	timeout     time.Duration
+}
+
+func (c *Chain) Name() string {
+	names := make([]string, 0, len(c.translators))
+	for _, t := range c.translators {
+		names = append(names, t.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+func (c *Chain) Translate(ctx context.Context, target string, texts ...string) ([]string, error) {
+	results, err := c.results(ctx, target, texts...)
+	translations := make([]string, len(results))
+	for i, r := range results {
+		translations[i] = r.Text
+	}
+
+	return translations, err
+}
+
+// results 依次使用各翻译服务，只将未翻译成功或译文未通过校验的条目交给下一个翻译服务
+func (c *Chain) results(ctx context.Context, target string, texts ...string) ([]Result, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if len(c.translators) == 0 {
+		return nil, ErrNoTranslator
+	}
+
+	results := make([]Result, len(texts))
+	pending := make([]int, 0, len(texts))
+	for i := range texts {
+		pending = append(pending, i)
+	}
+
+	for _, t := range c.translators {
+		batch := make([]string, 0, len(pending))
+		for _, i := range pending {
+			batch = append(batch, texts[i])
+		}
+
+		tctx, cancel := context.WithTimeout(ctx, c.timeout)
+		translations, err := t.Translate(tctx, target, batch...)
+		cancel()
+		if err == nil && len(translations) != len(batch) {
+			err = fmt.Errorf("%w: %d != %d", ErrMismatch, len(translations), len(batch))
+		}
+		if err != nil {
+			logger.Warnf("Translator %s failed: %s", t.Name(), err)
+			continue
+		}
+
+		next := make([]int, 0)
+		for j, i := range pending {
+			text := strings.TrimSpace(translations[j])
+			if text != "" {
+				if e := Validate(target, texts[i], text); e != nil {
+					logger.Warnf("Invalid translation of %s by %s: %s", texts[i], t.Name(), e)
+					text = ""
+				}
+			}
+			if text != "" {
+				violations := DefaultGlossary().Check(target, texts[i], text)
+				if len(violations) > 0 {
+					logger.Warnf("Translation of %s by %s violates glossary: %s", texts[i], t.Name(), strings.Join(violations, ", "))
+				}
+				results[i] = Result{Text: text, Translator: t.Name(), Model: modelOf(t), Violations: violations}
+			} else {
+				next = append(next, i)
+			}
+		}
+		if len(next) > 0 {
+			logger.Warnf("Translator %s missed %d of %d items", t.Name(), len(next), len(pending))
+		}
+		if pending = next; len(pending) == 0 {
+			return results, nil
+		}
+	}
+
+	return results, fmt.Errorf("%w: %d of %d items", ErrIncomplete, len(pending), len(texts))
+}
+
+// Complete 依次使用支持大模型对话的翻译服务，返回第一个非空回复
+func (c *Chain) Complete(ctx context.Context, system, user string) (string, error) {
+	var errs []error
+	for _, t := range c.translators {
+		completer, ok := t.(Completer)
+		if !ok {
+			continue
+		}
+
+		tctx, cancel := context.WithTimeout(ctx, c.timeout)
+		reply, err := completer.Complete(tctx, system, user)
+		cancel()
+		if err == nil && strings.TrimSpace(reply) != "" {
+			return reply, nil
+		}
+		if err == nil {
+			err = errors.New("empty reply")
+		}
+		logger.Warnf("Completer %s failed: %s", t.Name(), err)
+		errs = append(errs, fmt.Errorf("%s: %w", t.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return "", ErrNoCompleter
+	}
+
+	return "", errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"regexp"
+	"unicode"
+)
+
+// hanPerWord 汉字数折算为英文单词数
+const hanPerWord = 1.5
+
+// acronymRegexp 大写缩写、代币符号和网址，中英文文本中都常见，不用于判断语言
+var acronymRegexp = regexp.MustCompile(`https?://\S+|\$?\b[A-Z][A-Z0-9]{1,9}\b`)
+
+// DetectLang 根据文字判断中文或英文，汉字按字数折算为单词后与拉丁字母单词数比较。
+// 文字太少无法判断时返回空字符串
+func DetectLang(texts ...string) string {
+	var han, words int
+	for _, text := range texts {
+		inWord := false
+		for _, r := range acronymRegexp.ReplaceAllString(text, " ") {
+			switch {
+			case unicode.Is(unicode.Han, r):
+				han++
+				inWord = false
+			case unicode.In(r, unicode.Latin):
+				if !inWord {
+					words++
+				}
+				inWord = true
+			default:
+				inWord = false
+			}
+		}
+	}
+
+	zh := float64(han) / hanPerWord
+	switch {
+	case zh+float64(words) < 2:
+		return ""
+	case zh >= float64(words):
+		return "zh"
+	}
+
+	return "en"
+}
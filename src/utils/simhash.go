@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+)
+
+// SimHash 计算文本的64位SimHash，特征为规范化文本的4字符片段，标题较短时比按单词计算更稳定
+func SimHash(s string) uint64 {
+	const n = 4
+	runes := []rune(strings.Join(tokenize(s), " "))
+	features := make([]string, 0, len(runes))
+	for i := 0; i+n <= len(runes); i++ {
+		features = append(features, string(runes[i:i+n]))
+	}
+	if len(features) == 0 && len(runes) > 0 {
+		features = append(features, string(runes))
+	}
+	if len(features) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, feature := range features {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var hash uint64
+	for i := 0; i < 64; i++ {
+		if weights[i] > 0 {
+			hash |= 1 << uint(i)
+		}
+	}
+
+	return hash
+}
+
+// HammingDistance 两个SimHash的汉明距离
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// SimHashBands 将SimHash切分为4段16位，汉明距离不超过3的两个哈希至少有一段相同
+func SimHashBands(hash uint64) [4]uint16 {
+	var bands [4]uint16
+	for i := range bands {
+		bands[i] = uint16(hash >> (16 * uint(i)))
+	}
+
+	return bands
+}
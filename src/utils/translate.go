@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"github.com/northes/go-moonshot"
 	"news/src/config"
 	"news/src/logger"
@@ -54,6 +55,9 @@ func (t *Translate) Send(contents ...string) (string, error) {
 		logger.Errorf("Failed to send message to Kim: %s", err)
 		return "", err
 	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("empty response")
+	}
 
 	return resp.Choices[0].Message.Content, nil
 }
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams 跟踪参数，规范化时移除
+var trackingParams = map[string]struct{}{
+	"gclid":      {},
+	"fbclid":     {},
+	"msclkid":    {},
+	"dclid":      {},
+	"yclid":      {},
+	"mc_cid":     {},
+	"mc_eid":     {},
+	"igshid":     {},
+	"ref":        {},
+	"ref_src":    {},
+	"ref_url":    {},
+	"referrer":   {},
+	"spm":        {},
+	"share_from": {},
+	"_ga":        {},
+	"_gl":        {},
+	"cmpid":      {},
+	"guccounter": {},
+	"amp":        {},
+}
+
+// CanonicalURL URL规范化：统一协议和域名大小写，移除www前缀、默认端口、锚点、跟踪参数和末尾斜杠，参数按名称排序
+func CanonicalURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = host + ":" + port
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	if strings.HasSuffix(u.Path, "/amp") {
+		u.Path = strings.TrimSuffix(u.Path, "/amp")
+	}
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		lk := strings.ToLower(k)
+		if _, ok := trackingParams[lk]; ok || strings.HasPrefix(lk, "utm_") {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := query[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			values = append(values, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	u.RawQuery = strings.Join(values, "&")
+	u.ForceQuery = false
+
+	return u.String()
+}
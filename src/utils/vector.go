@@ -2,6 +2,7 @@ package utils
 
 import (
 	"math"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -41,34 +42,58 @@ func IsUniqueStrings(ss []string, sub string, threshold float64) bool {
 	return true
 }
 
+// Similarity 两个字符串的相似度
+func Similarity(s1, s2 string) float64 {
+	return cosineSimilarity(s1, s2)
+}
+
 // cosineSimilarity 计算两个字符串的余弦相似度
 func cosineSimilarity(s1, s2 string) float64 {
-	words1 := tokenize(s1)
-	words2 := tokenize(s2)
-
-	tf1 := termFrequency(words1)
-	tf2 := termFrequency(words2)
+	return NewVector(s1).Cosine(NewVector(s2))
+}
 
-	// 计算余弦相似度
-	dotProduct := 0.0
-	norm1 := 0.0
-	norm2 := 0.0
+// Vector 文本的词频向量，同一文本多次比较时只分词一次
+type Vector struct {
+	tf   map[string]int
+	norm float64
+}
 
-	for word, count1 := range tf1 {
-		count2 := tf2[word]
-		dotProduct += float64(count1 * count2)
-		norm1 += float64(count1 * count1)
+func NewVector(s string) Vector {
+	tf := termFrequency(tokenize(s))
+	norm := 0.0
+	for _, count := range tf {
+		norm += float64(count * count)
 	}
 
-	for _, count2 := range tf2 {
-		norm2 += float64(count2 * count2)
+	return Vector{tf: tf, norm: math.Sqrt(norm)}
+}
+
+// Terms 向量中的词
+func (v Vector) Terms() []string {
+	terms := make([]string, 0, len(v.tf))
+	for term := range v.tf {
+		terms = append(terms, term)
 	}
 
-	if norm1 == 0 || norm2 == 0 {
+	return terms
+}
+
+// Cosine 两个向量的余弦相似度
+func (v Vector) Cosine(o Vector) float64 {
+	if v.norm == 0 || o.norm == 0 {
 		return 0.0
 	}
 
-	return dotProduct / (math.Sqrt(norm1) * math.Sqrt(norm2))
+	a, b := v.tf, o.tf
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	dotProduct := 0
+	for word, count := range a {
+		dotProduct += count * b[word]
+	}
+
+	return float64(dotProduct) / (v.norm * o.norm)
 }
 
 // termFrequency 文本词频统计
@@ -80,11 +105,148 @@ func termFrequency(words []string) map[string]int {
 	return tf
 }
 
-// tokenize 文本分词
+// stopwords 英文停用词
+var stopwords = map[string]struct{}{
+	"a": {}, "an": {}, "the": {}, "of": {}, "to": {}, "in": {}, "on": {}, "for": {}, "and": {}, "or": {},
+	"as": {}, "at": {}, "by": {}, "with": {}, "from": {}, "into": {}, "over": {}, "after": {}, "amid": {},
+	"is": {}, "are": {}, "was": {}, "were": {}, "be": {}, "been": {}, "being": {}, "has": {}, "have": {}, "had": {},
+	"it": {}, "its": {}, "this": {}, "that": {}, "these": {}, "those": {}, "will": {}, "would": {}, "could": {},
+	"can": {}, "may": {}, "says": {}, "said": {}, "than": {}, "but": {}, "not": {}, "about": {}, "up": {}, "out": {},
+	"how": {}, "why": {}, "what": {}, "who": {}, "here": {}, "s": {},
+}
+
+// cjkStopwords 中文停用字，作为分隔符处理
+var cjkStopwords = map[rune]struct{}{
+	'的': {}, '了': {}, '和': {}, '与': {}, '及': {}, '或': {}, '等': {}, '着': {}, '吗': {}, '呢': {}, '吧': {},
+}
+
+// numberUnits 数字单位
+var numberUnits = map[string]float64{
+	"k": 1e3, "m": 1e6, "b": 1e9, "bn": 1e9, "t": 1e12, "万": 1e4, "亿": 1e8,
+}
+
+// tokenize 文本分词：中日韩文字按相邻两字切分，英文去除标点和停用词，代币符号去除$前缀，数字统一格式
 func tokenize(s string) []string {
-	s = strings.ToLower(s)
-	s = strings.TrimFunc(s, func(r rune) bool {
-		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
-	})
-	return strings.Fields(s)
+	runes := []rune(foldWidth(strings.ToLower(s)))
+	tokens := make([]string, 0, len(runes))
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			j := i
+			for j < len(runes) && isCJK(runes[j]) {
+				if _, ok := cjkStopwords[runes[j]]; ok {
+					break
+				}
+				j++
+			}
+			tokens = append(tokens, bigrams(runes[i:j])...)
+			if j == i { // 停用字
+				j++
+			}
+			i = j
+		case unicode.IsDigit(r) || (r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			token, j := readNumber(runes, i)
+			tokens = append(tokens, token)
+			i = j
+		case unicode.IsLetter(r) || (r == '$' && i+1 < len(runes) && unicode.IsLetter(runes[i+1])):
+			if r == '$' { // 代币符号
+				i++
+			}
+			j := i
+			for j < len(runes) && (isWordRune(runes[j]) || (runes[j] == '-' && j+1 < len(runes) && isWordRune(runes[j+1]))) {
+				j++
+			}
+			word := strings.TrimSuffix(strings.ReplaceAll(string(runes[i:j]), "’", "'"), "'s")
+			if _, ok := stopwords[word]; !ok {
+				tokens = append(tokens, word)
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// readNumber 读取数字，去除货币符号和千位分隔符，带单位的数字换算为完整数值，如 $70K、70,000、7万
+func readNumber(runes []rune, i int) (string, int) {
+	if runes[i] == '$' {
+		i++
+	}
+
+	digits := make([]rune, 0, 16)
+	j := i
+	for ; j < len(runes); j++ {
+		r := runes[j]
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+			continue
+		}
+		// 千位分隔符和小数点后必须是数字
+		if (r == ',' || r == '.') && j+1 < len(runes) && unicode.IsDigit(runes[j+1]) {
+			if r == '.' {
+				digits = append(digits, r)
+			}
+			continue
+		}
+		break
+	}
+
+	// 数字单位
+	unit := 1.0
+	for _, n := range []int{2, 1} {
+		if j+n > len(runes) {
+			continue
+		}
+		if u, ok := numberUnits[string(runes[j:j+n])]; ok && (j+n == len(runes) || !isWordRune(runes[j+n]) || isCJK(runes[j+n])) {
+			unit = u
+			j += n
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(string(digits), 64)
+	if err != nil {
+		return string(digits), j
+	}
+
+	return strconv.FormatFloat(value*unit, 'f', -1, 64), j
+}
+
+// bigrams 相邻两字切分，单字直接返回
+func bigrams(runes []rune) []string {
+	if len(runes) == 1 {
+		return []string{string(runes)}
+	}
+
+	tokens := make([]string, 0, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		tokens = append(tokens, string(runes[i:i+2]))
+	}
+
+	return tokens
+}
+
+// foldWidth 全角字符转换为半角
+func foldWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u3000':
+			return ' '
+		case r >= '\uff01' && r <= '\uff5e':
+			return r - 0xfee0
+		}
+		return r
+	}, s)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsNumber(r) || r == '\'' || r == '’') && !isCJK(r)
 }
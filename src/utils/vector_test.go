@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"Bitcoin Tops $70K as ETF Inflows Surge", []string{"bitcoin", "tops", "70000", "etf", "inflows", "surge"}},
+		{"BTC price hits $70,000", []string{"btc", "price", "hits", "70000"}},
+		{"Solana's $SOL rallies 12.5%", []string{"solana", "sol", "rallies", "12.5"}},
+		{"比特币突破7万美元", []string{"比特", "特币", "币突", "突破", "70000", "美元"}},
+		{"以太坊的现货ETF获批", []string{"以太", "太坊", "现货", "etf", "获批"}},
+		{"ＳＥＣ起诉Ｂｉｎａｎｃｅ", []string{"sec", "起诉", "binance"}},
+	}
+
+	for _, c := range cases {
+		if got := tokenize(c.text); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
+
+// 真实新闻标题，近似重复的标题相似度应高于事件聚合阈值，不同事件的标题应明显低于阈值
+var similarityCases = []struct {
+	name  string
+	a, b  string
+	equal bool
+}{
+	{
+		"en same story",
+		"Bitcoin Tops $70K for First Time Since March as ETF Inflows Surge",
+		"Bitcoin tops $70,000 for the first time since March as ETF inflows surge",
+		true,
+	},
+	{
+		"en reworded",
+		"SEC Approves Spot Ether ETFs in Surprise Reversal",
+		"SEC approves spot Ether ETFs in a surprise reversal of stance",
+		true,
+	},
+	{
+		"en distinct same coin",
+		"Bitcoin Tops $70K for First Time Since March as ETF Inflows Surge",
+		"Bitcoin Miners Sell Holdings Ahead of the Halving",
+		false,
+	},
+	{
+		"en distinct",
+		"Solana DEX Volume Hits Record as Memecoin Mania Returns",
+		"Coinbase Reports Q2 Earnings Beat on Trading Revenue",
+		false,
+	},
+	{
+		"zh same story",
+		"比特币突破7万美元，创三个月新高",
+		"比特币突破70000美元 创近三个月新高",
+		true,
+	},
+	{
+		"zh reworded",
+		"美国SEC批准以太坊现货ETF",
+		"美SEC正式批准以太坊现货ETF上市",
+		true,
+	},
+	{
+		"zh distinct same coin",
+		"比特币突破7万美元，创三个月新高",
+		"比特币矿工在减半前抛售持仓",
+		false,
+	},
+	{
+		"zh distinct",
+		"Solana链上DEX交易量创历史新高",
+		"Coinbase第二季度财报超预期",
+		false,
+	},
+}
+
+func TestSimilarity(t *testing.T) {
+	const threshold = 0.6 // 事件聚合默认阈值
+
+	for _, c := range similarityCases {
+		s := Similarity(c.a, c.b)
+		if c.equal && s < threshold {
+			t.Errorf("%s: Similarity(%q, %q) = %.2f, want >= %.2f", c.name, c.a, c.b, s, threshold)
+		}
+		if !c.equal && s >= threshold/2 {
+			t.Errorf("%s: Similarity(%q, %q) = %.2f, want < %.2f", c.name, c.a, c.b, s, threshold/2)
+		}
+	}
+}
+
+func BenchmarkTokenize(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, c := range similarityCases {
+			tokenize(c.a)
+		}
+	}
+}
+
+func BenchmarkSimilarity(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, c := range similarityCases {
+			Similarity(c.a, c.b)
+		}
+	}
+}